@@ -0,0 +1,240 @@
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/auth"
+	"github.com/Kilat-Pet-Delivery/lib-common/reqctx"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// API key errors.
+var (
+	ErrInvalidKey = errors.New("invalid API key")
+	ErrNotFound   = errors.New("API key not found")
+	ErrExpired    = errors.New("API key has expired")
+	ErrRevoked    = errors.New("API key has been revoked")
+	ErrRole       = errors.New("API keys can only be issued for the shop role")
+)
+
+// DefaultKeyPrefix is the fixed leading segment that marks a Kilat API key.
+const DefaultKeyPrefix = "kpk"
+
+const (
+	lookupLength = 12
+	secretBytes  = 32
+	// lastUsedResolution limits how often last-used timestamps are written.
+	lastUsedResolution = time.Minute
+)
+
+// APIKey is a stored API key. Only the SHA-256 hash of the full key is kept;
+// Prefix is the public lookup segment.
+type APIKey struct {
+	ID         uuid.UUID
+	Prefix     string
+	Hash       string
+	OwnerID    uuid.UUID
+	Role       auth.UserRole
+	Name       string
+	Scopes     []string
+	CreatedAt  time.Time
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+}
+
+// HasScope returns true if the key was granted the scope.
+func (k *APIKey) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope || s == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// IsExpired returns true if the key has an expiry in the past.
+func (k *APIKey) IsExpired() bool {
+	return k.ExpiresAt != nil && time.Now().After(*k.ExpiresAt)
+}
+
+// IsRevoked returns true if the key has been revoked.
+func (k *APIKey) IsRevoked() bool {
+	return k.RevokedAt != nil
+}
+
+// Store persists API keys.
+type Store interface {
+	Create(ctx context.Context, key *APIKey) error
+	// GetByPrefix returns the key with the given lookup prefix or ErrNotFound.
+	GetByPrefix(ctx context.Context, prefix string) (*APIKey, error)
+	ListByOwner(ctx context.Context, ownerID uuid.UUID) ([]*APIKey, error)
+	TouchLastUsed(ctx context.Context, id uuid.UUID, at time.Time) error
+	Revoke(ctx context.Context, id uuid.UUID) error
+}
+
+// IssueRequest describes a new API key.
+type IssueRequest struct {
+	OwnerID uuid.UUID
+	Role    auth.UserRole
+	Name    string
+	Scopes  []string
+	// TTL is the key lifetime; zero means the key does not expire.
+	TTL time.Duration
+}
+
+// Manager issues and authenticates API keys.
+type Manager struct {
+	store     Store
+	keyPrefix string
+	logger    *zap.Logger
+}
+
+// NewManager creates an API key manager. logger records failures of
+// best-effort bookkeeping such as last-used updates.
+func NewManager(store Store, logger *zap.Logger) *Manager {
+	return &Manager{store: store, keyPrefix: DefaultKeyPrefix, logger: logger}
+}
+
+// Issue creates a new key and returns its plaintext form, which is shown
+// to the caller once and never stored. Keys are for partner shops, so Role
+// must be empty or auth.RoleShop.
+func (m *Manager) Issue(ctx context.Context, req IssueRequest) (string, *APIKey, error) {
+	role := req.Role
+	if role == "" {
+		role = auth.RoleShop
+	}
+	if role != auth.RoleShop {
+		return "", nil, fmt.Errorf("%w: %q", ErrRole, role)
+	}
+
+	lookup, err := randomString(lookupLength)
+	if err != nil {
+		return "", nil, err
+	}
+	secret, err := randomSecret(secretBytes)
+	if err != nil {
+		return "", nil, err
+	}
+	plaintext := fmt.Sprintf("%s_%s_%s", m.keyPrefix, lookup, secret)
+
+	now := time.Now().UTC()
+	key := &APIKey{
+		ID:        uuid.New(),
+		Prefix:    lookup,
+		Hash:      hashKey(plaintext),
+		OwnerID:   req.OwnerID,
+		Role:      role,
+		Name:      req.Name,
+		Scopes:    req.Scopes,
+		CreatedAt: now,
+	}
+	if req.TTL > 0 {
+		expiresAt := now.Add(req.TTL)
+		key.ExpiresAt = &expiresAt
+	}
+
+	if err := m.store.Create(ctx, key); err != nil {
+		return "", nil, fmt.Errorf("failed to store API key: %w", err)
+	}
+	return plaintext, key, nil
+}
+
+// Authenticate looks up a plaintext key by prefix, verifies its hash and
+// checks expiry and revocation. Last-used time is updated at most once a
+// minute; a failed update is logged and does not fail authentication.
+func (m *Manager) Authenticate(ctx context.Context, plaintext string) (*APIKey, error) {
+	lookup, ok := m.parse(plaintext)
+	if !ok {
+		return nil, ErrInvalidKey
+	}
+
+	key, err := m.store.GetByPrefix(ctx, lookup)
+	if errors.Is(err, ErrNotFound) {
+		return nil, ErrInvalidKey
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(key.Hash), []byte(hashKey(plaintext))) != 1 {
+		return nil, ErrInvalidKey
+	}
+	if key.IsRevoked() {
+		return nil, ErrRevoked
+	}
+	if key.IsExpired() {
+		return nil, ErrExpired
+	}
+
+	now := time.Now().UTC()
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= lastUsedResolution {
+		if err := m.store.TouchLastUsed(ctx, key.ID, now); err != nil {
+			reqctx.Logger(ctx, m.logger).Warn("failed to record API key usage",
+				zap.String("key_id", key.ID.String()),
+				zap.Error(err),
+			)
+		} else {
+			key.LastUsedAt = &now
+		}
+	}
+	return key, nil
+}
+
+// List returns every key belonging to an owner.
+func (m *Manager) List(ctx context.Context, ownerID uuid.UUID) ([]*APIKey, error) {
+	return m.store.ListByOwner(ctx, ownerID)
+}
+
+// Revoke revokes a key by ID.
+func (m *Manager) Revoke(ctx context.Context, id uuid.UUID) error {
+	return m.store.Revoke(ctx, id)
+}
+
+// parse splits "<prefix>_<lookup>_<secret>" and returns the lookup segment.
+func (m *Manager) parse(plaintext string) (string, bool) {
+	parts := strings.Split(plaintext, "_")
+	if len(parts) != 3 || parts[0] != m.keyPrefix || len(parts[1]) != lookupLength || parts[2] == "" {
+		return "", false
+	}
+	return parts[1], true
+}
+
+// hashKey returns the hex SHA-256 of the full plaintext key. Keys carry 256
+// bits of randomness, so a fast hash is sufficient.
+func hashKey(plaintext string) string {
+	sum := sha256.Sum256([]byte(plaintext))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomSecret returns n random bytes hex encoded, so the secret segment
+// keeps all n*8 bits and contains no underscores.
+func randomSecret(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// randomString returns an n character base64url string without '_' so the
+// result can be split on underscores. It is used for the public lookup segment.
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	s := base64.RawURLEncoding.EncodeToString(b)
+	s = strings.NewReplacer("_", "x", "-", "y").Replace(s)
+	return s[:n], nil
+}
@@ -0,0 +1,59 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// touchFailingStore is a MemoryStore whose last-used updates always fail.
+type touchFailingStore struct {
+	*MemoryStore
+}
+
+func (touchFailingStore) TouchLastUsed(context.Context, uuid.UUID, time.Time) error {
+	return errors.New("connection refused")
+}
+
+func TestAuthenticateIgnoresTouchFailure(t *testing.T) {
+	ctx := context.Background()
+	m := NewManager(touchFailingStore{NewMemoryStore()}, zap.NewNop())
+
+	plaintext, issued, err := m.Issue(ctx, IssueRequest{OwnerID: uuid.New(), Name: "pos"})
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	key, err := m.Authenticate(ctx, plaintext)
+	if err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+	if key.ID != issued.ID {
+		t.Fatalf("authenticated key %s, want %s", key.ID, issued.ID)
+	}
+	if key.LastUsedAt != nil {
+		t.Fatalf("LastUsedAt = %v after a failed update, want nil", key.LastUsedAt)
+	}
+}
+
+func TestRevoke(t *testing.T) {
+	ctx := context.Background()
+	m := NewManager(NewMemoryStore(), zap.NewNop())
+
+	plaintext, issued, err := m.Issue(ctx, IssueRequest{OwnerID: uuid.New()})
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	if err := m.Revoke(ctx, issued.ID); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	if _, err := m.Authenticate(ctx, plaintext); !errors.Is(err, ErrRevoked) {
+		t.Fatalf("Authenticate after Revoke: got %v, want ErrRevoked", err)
+	}
+	if err := m.Revoke(ctx, uuid.New()); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Revoke unknown key: got %v, want ErrNotFound", err)
+	}
+}
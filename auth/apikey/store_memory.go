@@ -0,0 +1,83 @@
+package apikey
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MemoryStore is an in-process Store for tests and single-instance deployments.
+type MemoryStore struct {
+	mu   sync.RWMutex
+	keys map[uuid.UUID]*APIKey
+}
+
+// NewMemoryStore creates an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{keys: make(map[uuid.UUID]*APIKey)}
+}
+
+// Create stores a new key.
+func (s *MemoryStore) Create(_ context.Context, key *APIKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k := *key
+	s.keys[key.ID] = &k
+	return nil
+}
+
+// GetByPrefix returns the key with the given lookup prefix.
+func (s *MemoryStore) GetByPrefix(_ context.Context, prefix string) (*APIKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, k := range s.keys {
+		if k.Prefix == prefix {
+			out := *k
+			return &out, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// ListByOwner returns every key belonging to an owner.
+func (s *MemoryStore) ListByOwner(_ context.Context, ownerID uuid.UUID) ([]*APIKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	keys := make([]*APIKey, 0)
+	for _, k := range s.keys {
+		if k.OwnerID == ownerID {
+			out := *k
+			keys = append(keys, &out)
+		}
+	}
+	return keys, nil
+}
+
+// TouchLastUsed records when a key was last used.
+func (s *MemoryStore) TouchLastUsed(_ context.Context, id uuid.UUID, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k, ok := s.keys[id]
+	if !ok {
+		return ErrNotFound
+	}
+	k.LastUsedAt = &at
+	return nil
+}
+
+// Revoke revokes a key.
+func (s *MemoryStore) Revoke(_ context.Context, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k, ok := s.keys[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if k.RevokedAt == nil {
+		now := time.Now().UTC()
+		k.RevokedAt = &now
+	}
+	return nil
+}
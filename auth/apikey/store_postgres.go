@@ -0,0 +1,137 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/auth"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// apiKeyModel is the GORM model for the api_keys table.
+type apiKeyModel struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Prefix     string    `gorm:"not null;uniqueIndex"`
+	Hash       string    `gorm:"not null"`
+	OwnerID    uuid.UUID `gorm:"type:uuid;not null;index"`
+	Role       string    `gorm:"not null"`
+	Name       string    `gorm:"not null;default:''"`
+	Scopes     string    `gorm:"not null;default:''"`
+	CreatedAt  time.Time `gorm:"not null"`
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+}
+
+// TableName sets the table name for GORM.
+func (apiKeyModel) TableName() string { return "api_keys" }
+
+func (m *apiKeyModel) toAPIKey() *APIKey {
+	return &APIKey{
+		ID:         m.ID,
+		Prefix:     m.Prefix,
+		Hash:       m.Hash,
+		OwnerID:    m.OwnerID,
+		Role:       auth.UserRole(m.Role),
+		Name:       m.Name,
+		Scopes:     strings.Fields(m.Scopes),
+		CreatedAt:  m.CreatedAt,
+		ExpiresAt:  m.ExpiresAt,
+		LastUsedAt: m.LastUsedAt,
+		RevokedAt:  m.RevokedAt,
+	}
+}
+
+// PostgresStore is a GORM-backed Store.
+type PostgresStore struct {
+	db *gorm.DB
+}
+
+// NewPostgresStore creates a Postgres API key store.
+func NewPostgresStore(db *gorm.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+// AutoMigrate creates or updates the api_keys table.
+func (s *PostgresStore) AutoMigrate() error {
+	return s.db.AutoMigrate(&apiKeyModel{})
+}
+
+// Create stores a new key.
+func (s *PostgresStore) Create(ctx context.Context, key *APIKey) error {
+	model := apiKeyModel{
+		ID:        key.ID,
+		Prefix:    key.Prefix,
+		Hash:      key.Hash,
+		OwnerID:   key.OwnerID,
+		Role:      string(key.Role),
+		Name:      key.Name,
+		Scopes:    strings.Join(key.Scopes, " "),
+		CreatedAt: key.CreatedAt,
+		ExpiresAt: key.ExpiresAt,
+	}
+	if err := s.db.WithContext(ctx).Create(&model).Error; err != nil {
+		return fmt.Errorf("failed to create API key: %w", err)
+	}
+	return nil
+}
+
+// GetByPrefix returns the key with the given lookup prefix.
+func (s *PostgresStore) GetByPrefix(ctx context.Context, prefix string) (*APIKey, error) {
+	var model apiKeyModel
+	err := s.db.WithContext(ctx).Where("prefix = ?", prefix).First(&model).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load API key: %w", err)
+	}
+	return model.toAPIKey(), nil
+}
+
+// ListByOwner returns every key belonging to an owner, newest first.
+func (s *PostgresStore) ListByOwner(ctx context.Context, ownerID uuid.UUID) ([]*APIKey, error) {
+	var models []apiKeyModel
+	err := s.db.WithContext(ctx).Where("owner_id = ?", ownerID).Order("created_at DESC").Find(&models).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+	keys := make([]*APIKey, 0, len(models))
+	for i := range models {
+		keys = append(keys, models[i].toAPIKey())
+	}
+	return keys, nil
+}
+
+// TouchLastUsed records when a key was last used.
+func (s *PostgresStore) TouchLastUsed(ctx context.Context, id uuid.UUID, at time.Time) error {
+	err := s.db.WithContext(ctx).Model(&apiKeyModel{}).Where("id = ?", id).Update("last_used_at", at).Error
+	if err != nil {
+		return fmt.Errorf("failed to update API key last used: %w", err)
+	}
+	return nil
+}
+
+// Revoke revokes a key.
+func (s *PostgresStore) Revoke(ctx context.Context, id uuid.UUID) error {
+	result := s.db.WithContext(ctx).Model(&apiKeyModel{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now().UTC())
+	if result.Error != nil {
+		return fmt.Errorf("failed to revoke API key: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		var count int64
+		if err := s.db.WithContext(ctx).Model(&apiKeyModel{}).Where("id = ?", id).Count(&count).Error; err != nil {
+			return fmt.Errorf("failed to revoke API key: %w", err)
+		}
+		if count == 0 {
+			return ErrNotFound
+		}
+	}
+	return nil
+}
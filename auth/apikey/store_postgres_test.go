@@ -0,0 +1,71 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newMockPostgresStore returns a PostgresStore backed by sqlmock standing in
+// for a Postgres server.
+func newMockPostgresStore(t *testing.T) (*PostgresStore, sqlmock.Sqlmock) {
+	t.Helper()
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open: %v", err)
+	}
+	return NewPostgresStore(db), mock
+}
+
+func TestPostgresStoreRevoke(t *testing.T) {
+	tests := []struct {
+		name    string
+		updated int64
+		exists  int64
+		want    error
+	}{
+		{name: "active key", updated: 1},
+		{name: "already revoked", updated: 0, exists: 1},
+		{name: "unknown key", updated: 0, exists: 0, want: ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, mock := newMockPostgresStore(t)
+			id := uuid.New()
+
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta(`UPDATE "api_keys" SET "revoked_at"=$1 WHERE id = $2 AND revoked_at IS NULL`)).
+				WithArgs(sqlmock.AnyArg(), id).
+				WillReturnResult(sqlmock.NewResult(0, tt.updated))
+			mock.ExpectCommit()
+			if tt.updated == 0 {
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "api_keys" WHERE id = $1`)).
+					WithArgs(id).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(tt.exists))
+			}
+
+			err := store.Revoke(context.Background(), id)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Revoke: got %v, want %v", err, tt.want)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Audit event types.
+const (
+	AuditAccessDecision = "authz.decision"
+)
+
+// AuditEvent is a security-relevant decision or action.
+type AuditEvent struct {
+	Type       string                 `json:"type"`
+	ActorID    uuid.UUID              `json:"actor_id"`
+	ActorRole  UserRole               `json:"actor_role"`
+	Resource   string                 `json:"resource,omitempty"`
+	ResourceID string                 `json:"resource_id,omitempty"`
+	Action     string                 `json:"action,omitempty"`
+	Allowed    bool                   `json:"allowed"`
+	Reason     string                 `json:"reason,omitempty"`
+	Metadata   map[string]interface{} `json:"metadata,omitempty"`
+	OccurredAt time.Time              `json:"occurred_at"`
+}
+
+// Auditor records audit events.
+type Auditor interface {
+	Audit(ctx context.Context, event AuditEvent)
+}
+
+// AuditorFunc adapts a function to the Auditor interface.
+type AuditorFunc func(ctx context.Context, event AuditEvent)
+
+// Audit calls f(ctx, event).
+func (f AuditorFunc) Audit(ctx context.Context, event AuditEvent) {
+	f(ctx, event)
+}
+
+// ZapAuditor writes audit events to a Zap logger.
+type ZapAuditor struct {
+	logger *zap.Logger
+}
+
+// NewZapAuditor creates an auditor that logs to the given logger.
+func NewZapAuditor(logger *zap.Logger) *ZapAuditor {
+	return &ZapAuditor{logger: logger.Named("audit")}
+}
+
+// Audit logs the event. Denied decisions are logged at warn level.
+func (a *ZapAuditor) Audit(_ context.Context, event AuditEvent) {
+	fields := []zap.Field{
+		zap.String("type", event.Type),
+		zap.String("actor_id", event.ActorID.String()),
+		zap.String("actor_role", string(event.ActorRole)),
+		zap.String("resource", event.Resource),
+		zap.String("resource_id", event.ResourceID),
+		zap.String("action", event.Action),
+		zap.Bool("allowed", event.Allowed),
+		zap.String("reason", event.Reason),
+		zap.Time("occurred_at", event.OccurredAt),
+	}
+	if len(event.Metadata) > 0 {
+		fields = append(fields, zap.Any("metadata", event.Metadata))
+	}
+
+	if event.Allowed {
+		a.logger.Info("audit", fields...)
+	} else {
+		a.logger.Warn("audit", fields...)
+	}
+}
@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryConsumedTokenStore is an in-process ConsumedTokenStore.
+type MemoryConsumedTokenStore struct {
+	mu     sync.Mutex
+	tokens map[string]time.Time
+}
+
+// NewMemoryConsumedTokenStore creates an empty in-memory consumed token store.
+func NewMemoryConsumedTokenStore() *MemoryConsumedTokenStore {
+	return &MemoryConsumedTokenStore{tokens: make(map[string]time.Time)}
+}
+
+// Consume marks jti as used until expiresAt.
+func (s *MemoryConsumedTokenStore) Consume(_ context.Context, jti string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.tokens[jti]; ok {
+		return ErrTokenConsumed
+	}
+	s.tokens[jti] = expiresAt
+	return nil
+}
+
+// DeleteExpired removes entries whose tokens can no longer validate.
+func (s *MemoryConsumedTokenStore) DeleteExpired(_ context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	var n int64
+	for jti, expiresAt := range s.tokens {
+		if !now.Before(expiresAt) {
+			delete(s.tokens, jti)
+			n++
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// consumedTokenModel is the GORM model for the consumed_tokens table.
+type consumedTokenModel struct {
+	JTI        string    `gorm:"column:jti;primaryKey"`
+	ConsumedAt time.Time `gorm:"not null"`
+	ExpiresAt  time.Time `gorm:"not null;index"`
+}
+
+// TableName sets the table name for GORM.
+func (consumedTokenModel) TableName() string { return "consumed_tokens" }
+
+// PostgresConsumedTokenStore is a GORM-backed ConsumedTokenStore.
+type PostgresConsumedTokenStore struct {
+	db *gorm.DB
+}
+
+// NewPostgresConsumedTokenStore creates a Postgres consumed token store.
+func NewPostgresConsumedTokenStore(db *gorm.DB) *PostgresConsumedTokenStore {
+	return &PostgresConsumedTokenStore{db: db}
+}
+
+// AutoMigrate creates or updates the consumed_tokens table.
+func (s *PostgresConsumedTokenStore) AutoMigrate() error {
+	return s.db.AutoMigrate(&consumedTokenModel{})
+}
+
+// Consume marks jti as used until expiresAt. The insert is a no-op when the
+// jti already exists, which is how concurrent redemptions are detected.
+func (s *PostgresConsumedTokenStore) Consume(ctx context.Context, jti string, expiresAt time.Time) error {
+	result := s.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&consumedTokenModel{JTI: jti, ConsumedAt: time.Now().UTC(), ExpiresAt: expiresAt})
+	if result.Error != nil {
+		return fmt.Errorf("failed to consume token: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrTokenConsumed
+	}
+	return nil
+}
+
+// DeleteExpired removes entries whose tokens can no longer validate.
+func (s *PostgresConsumedTokenStore) DeleteExpired(ctx context.Context) (int64, error) {
+	result := s.db.WithContext(ctx).Where("expires_at < ?", time.Now().UTC()).Delete(&consumedTokenModel{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete expired consumed tokens: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
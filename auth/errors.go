@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Token validation errors. ValidateToken wraps every failure in one of
+// these so callers can branch with errors.Is.
+var (
+	ErrInvalidToken     = errors.New("invalid token")
+	ErrTokenMalformed   = errors.New("token is malformed")
+	ErrTokenExpired     = errors.New("token is expired")
+	ErrTokenNotYetValid = errors.New("token is not valid yet")
+	ErrInvalidIssuer    = errors.New("token issuer is not accepted")
+	ErrInvalidAudience  = errors.New("token audience is not accepted")
+	ErrInvalidSignature = errors.New("token signature is invalid")
+	ErrWrongTokenType   = errors.New("token has the wrong type")
+)
+
+// classifyParseError maps a jwt parse error onto the package's typed errors.
+func classifyParseError(err error) error {
+	var kind error
+	switch {
+	case errors.Is(err, ErrUnknownKeyID):
+		kind = ErrUnknownKeyID
+	case errors.Is(err, jwt.ErrTokenExpired):
+		kind = ErrTokenExpired
+	case errors.Is(err, jwt.ErrTokenNotValidYet), errors.Is(err, jwt.ErrTokenUsedBeforeIssued):
+		kind = ErrTokenNotYetValid
+	case errors.Is(err, jwt.ErrTokenInvalidIssuer):
+		kind = ErrInvalidIssuer
+	case errors.Is(err, jwt.ErrTokenInvalidAudience):
+		kind = ErrInvalidAudience
+	case errors.Is(err, jwt.ErrTokenSignatureInvalid), errors.Is(err, ErrInvalidSignature):
+		kind = ErrInvalidSignature
+	case errors.Is(err, jwt.ErrTokenMalformed):
+		kind = ErrTokenMalformed
+	default:
+		kind = ErrInvalidToken
+	}
+	return fmt.Errorf("%w: %w", kind, err)
+}
+
+// tokenErrors are the errors that mean a token was rejected, as opposed to
+// validation failing because a store or key source could not be reached.
+var tokenErrors = []error{
+	ErrInvalidToken,
+	ErrTokenMalformed,
+	ErrTokenExpired,
+	ErrTokenNotYetValid,
+	ErrInvalidIssuer,
+	ErrInvalidAudience,
+	ErrInvalidSignature,
+	ErrWrongTokenType,
+	ErrUnknownKeyID,
+	ErrTokenRevoked,
+}
+
+// IsTokenError reports whether err means the token itself was rejected.
+// Any other validation error means the check could not be completed, for
+// example because the revocation store is unreachable, and should not be
+// reported to the client as an invalid token.
+func IsTokenError(err error) bool {
+	for _, target := range tokenErrors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
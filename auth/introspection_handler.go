@@ -0,0 +1,109 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IntrospectionPath is the conventional path of the RFC 7662 introspection endpoint.
+const IntrospectionPath = "/oauth2/introspect"
+
+// IntrospectionResponse is an RFC 7662 token introspection response.
+// Inactive tokens carry nothing but active=false. Act identifies the admin
+// behind an impersonation token, as in RFC 8693.
+type IntrospectionResponse struct {
+	Active    bool     `json:"active"`
+	Scope     string   `json:"scope,omitempty"`
+	Username  string   `json:"username,omitempty"`
+	TokenType string   `json:"token_type,omitempty"`
+	Exp       int64    `json:"exp,omitempty"`
+	Iat       int64    `json:"iat,omitempty"`
+	Nbf       int64    `json:"nbf,omitempty"`
+	Sub       string   `json:"sub,omitempty"`
+	Aud       []string `json:"aud,omitempty"`
+	Iss       string   `json:"iss,omitempty"`
+	Jti       string   `json:"jti,omitempty"`
+	Role      UserRole `json:"role,omitempty"`
+	Act       *Actor   `json:"act,omitempty"`
+}
+
+// Introspect validates a JWT or opaque access token and describes it.
+// Rejected tokens, including refresh, service and purpose tokens, are
+// reported as inactive along with the reason. If validation could not be
+// completed, for example because a store is down, the response is nil.
+func (m *JWTManager) Introspect(ctx context.Context, token string) (*IntrospectionResponse, error) {
+	claims, err := m.ValidateTokenContext(ctx, token)
+	if err != nil {
+		if !IsTokenError(err) {
+			return nil, err
+		}
+		return &IntrospectionResponse{Active: false}, err
+	}
+	if claims.TokenType != AccessToken {
+		return &IntrospectionResponse{Active: false}, fmt.Errorf("%w: not an access token", ErrWrongTokenType)
+	}
+
+	resp := &IntrospectionResponse{
+		Active:    true,
+		Scope:     claims.Scope,
+		Username:  claims.Email,
+		TokenType: string(claims.TokenType),
+		Sub:       claims.Subject,
+		Aud:       claims.Audience,
+		Iss:       claims.Issuer,
+		Jti:       claims.ID,
+		Role:      claims.Role,
+		Act:       claims.Actor,
+	}
+	if claims.ExpiresAt != nil {
+		resp.Exp = claims.ExpiresAt.Unix()
+	}
+	if claims.IssuedAt != nil {
+		resp.Iat = claims.IssuedAt.Unix()
+	}
+	if claims.NotBefore != nil {
+		resp.Nbf = claims.NotBefore.Unix()
+	}
+	return resp, nil
+}
+
+// IntrospectionHandler serves RFC 7662 token introspection. The token is
+// read from the "token" form field. RFC 7662 requires callers to
+// authenticate, so mount it behind service-token or API key middleware.
+func IntrospectionHandler(m *JWTManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.PostForm("token")
+		if token == "" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":             "invalid_request",
+				"error_description": "token is required",
+			})
+			return
+		}
+
+		resp, err := m.Introspect(c.Request.Context(), token)
+		if err != nil {
+			_ = c.Error(err)
+		}
+		c.Header("Cache-Control", "no-store")
+		if resp == nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":             "server_error",
+				"error_description": "token could not be introspected",
+			})
+			return
+		}
+		c.JSON(http.StatusOK, resp)
+	}
+}
+
+// RegisterIntrospectionRoute adds the introspection endpoint to the router
+// behind the given authentication handlers. At least one guard is required
+// so the endpoint cannot be used to probe tokens anonymously.
+func RegisterIntrospectionRoute(r gin.IRoutes, m *JWTManager, guard gin.HandlerFunc, guards ...gin.HandlerFunc) {
+	handlers := append([]gin.HandlerFunc{guard}, guards...)
+	r.POST(IntrospectionPath, append(handlers, IntrospectionHandler(m))...)
+}
@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestIntrospect(t *testing.T) {
+	ctx := context.Background()
+	m := NewJWTManager("test-secret", time.Minute, time.Hour)
+	userID := uuid.New()
+
+	access, err := m.GenerateAccessToken(userID, "owner@example.com", RoleOwner, WithScopes("bookings:read"))
+	if err != nil {
+		t.Fatalf("GenerateAccessToken: %v", err)
+	}
+	resp, err := m.Introspect(ctx, access)
+	if err != nil {
+		t.Fatalf("Introspect: %v", err)
+	}
+	if !resp.Active || resp.Sub != userID.String() || resp.Scope != "bookings:read" || resp.Role != RoleOwner {
+		t.Fatalf("access token response = %+v", resp)
+	}
+
+	refresh, err := m.GenerateRefreshToken(userID)
+	if err != nil {
+		t.Fatalf("GenerateRefreshToken: %v", err)
+	}
+	resp, err = m.Introspect(ctx, refresh)
+	if err == nil || resp == nil || resp.Active {
+		t.Fatalf("refresh token: got %+v, %v, want inactive", resp, err)
+	}
+}
+
+func TestIntrospectionHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	m := NewJWTManager("test-secret", time.Minute, time.Hour)
+	r := gin.New()
+	RegisterIntrospectionRoute(r, m, func(c *gin.Context) { c.Next() })
+
+	token, err := m.GenerateAccessToken(uuid.New(), "owner@example.com", RoleOwner)
+	if err != nil {
+		t.Fatalf("GenerateAccessToken: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, IntrospectionPath, strings.NewReader(url.Values{"token": {token}}.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if body["active"] != true {
+		t.Fatalf("active = %v, want true", body["active"])
+	}
+	if _, ok := body["client_id"]; ok {
+		t.Fatalf("response carries client_id %v, which is never known", body["client_id"])
+	}
+}
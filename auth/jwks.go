@@ -0,0 +1,171 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+)
+
+// JWK is a JSON Web Key (RFC 7517) describing a public verification key.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid,omitempty"`
+	Algorithm string `json:"alg,omitempty"`
+	Use       string `json:"use,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	X         string `json:"x,omitempty"`
+	Y         string `json:"y,omitempty"`
+}
+
+// JWKSet is a JSON Web Key Set as served from /.well-known/jwks.json.
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// NewJWKSet builds a key set from the public half of the given keys.
+// Symmetric keys are skipped since they must never be published.
+func NewJWKSet(keys []*Key) (JWKSet, error) {
+	set := JWKSet{Keys: make([]JWK, 0, len(keys))}
+	for _, k := range keys {
+		if k.IsSymmetric() {
+			continue
+		}
+		jwk, err := k.JWK()
+		if err != nil {
+			return JWKSet{}, err
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set, nil
+}
+
+// JWK returns the public JSON Web Key representation of the key.
+func (k *Key) JWK() (JWK, error) {
+	jwk := JWK{KeyID: k.ID, Algorithm: k.Algorithm, Use: "sig"}
+
+	switch pub := k.public.(type) {
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = b64(pub.N.Bytes())
+		jwk.E = b64(big.NewInt(int64(pub.E)).Bytes())
+	case *ecdsa.PublicKey:
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		jwk.KeyType = "EC"
+		jwk.Curve = pub.Curve.Params().Name
+		jwk.X = b64(pub.X.FillBytes(make([]byte, size)))
+		jwk.Y = b64(pub.Y.FillBytes(make([]byte, size)))
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = b64(pub)
+	default:
+		return JWK{}, fmt.Errorf("%w: %T", ErrUnsupportedKeyType, k.public)
+	}
+	return jwk, nil
+}
+
+// Thumbprint returns the RFC 7638 SHA-256 thumbprint of an asymmetric key's
+// public half, base64url encoded. It is used as the kid of keys configured
+// without one.
+func (k *Key) Thumbprint() (string, error) {
+	jwk, err := k.JWK()
+	if err != nil {
+		return "", err
+	}
+
+	// RFC 7638 hashes only the required members, in lexicographic order.
+	var members interface{}
+	switch jwk.KeyType {
+	case "RSA":
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.KeyType, jwk.N}
+	case "EC":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		}{jwk.Curve, jwk.KeyType, jwk.X, jwk.Y}
+	default:
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{jwk.Curve, jwk.KeyType, jwk.X}
+	}
+	data, err := json.Marshal(members)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode JWK thumbprint members: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return b64(sum[:]), nil
+}
+
+// Key converts the JWK into a verification-only key.
+func (j JWK) Key() (*Key, error) {
+	switch j.KeyType {
+	case "RSA":
+		n, err := unb64(j.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RSA modulus for key %q: %w", j.KeyID, err)
+		}
+		e, err := unb64(j.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RSA exponent for key %q: %w", j.KeyID, err)
+		}
+		pub := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		return NewRSAPublicKey(j.KeyID, pub), nil
+	case "EC":
+		var curve elliptic.Curve
+		switch j.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("%w: EC curve %q", ErrUnsupportedKeyType, j.Curve)
+		}
+		x, err := unb64(j.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid EC x coordinate for key %q: %w", j.KeyID, err)
+		}
+		y, err := unb64(j.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid EC y coordinate for key %q: %w", j.KeyID, err)
+		}
+		pub := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		return NewECDSAPublicKey(j.KeyID, pub)
+	case "OKP":
+		if j.Curve != "Ed25519" {
+			return nil, fmt.Errorf("%w: OKP curve %q", ErrUnsupportedKeyType, j.Curve)
+		}
+		x, err := unb64(j.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 public key for key %q", j.KeyID)
+		}
+		return NewEd25519PublicKey(j.KeyID, ed25519.PublicKey(x)), nil
+	default:
+		return nil, fmt.Errorf("%w: kty %q", ErrUnsupportedKeyType, j.KeyType)
+	}
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func unb64(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(s)
+}
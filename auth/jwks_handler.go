@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JWKSPath is the well-known path for publishing the JSON Web Key Set.
+const JWKSPath = "/.well-known/jwks.json"
+
+// JWKSHandler serves the manager's public keys as a JSON Web Key Set.
+// Retired keys are included until they expire so in-flight tokens keep validating.
+func JWKSHandler(m *JWTManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		set, err := NewJWKSet(m.PublicKeys())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to build key set",
+			})
+			return
+		}
+		c.Header("Cache-Control", "public, max-age=300")
+		c.JSON(http.StatusOK, set)
+	}
+}
+
+// RegisterJWKSRoute adds the JWKS endpoint to the router.
+func RegisterJWKSRoute(r gin.IRoutes, m *JWTManager) {
+	r.GET(JWKSPath, JWKSHandler(m))
+}
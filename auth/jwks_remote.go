@@ -0,0 +1,209 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/config"
+	"go.uber.org/zap"
+)
+
+// Remote JWKS errors.
+var (
+	ErrInvalidRefreshInterval = errors.New("JWKS refresh interval must be positive")
+	ErrJWKSAlreadyStarted     = errors.New("JWKS refresh is already started")
+)
+
+// RemoteJWKS is a KeySource that fetches a JSON Web Key Set from a URL,
+// caches it and refreshes it in the background. Unknown key IDs trigger an
+// early refresh, throttled by MinRefreshInterval, so newly rotated keys are
+// picked up without waiting for the next tick.
+type RemoteJWKS struct {
+	url                string
+	client             *http.Client
+	keyring            *Keyring
+	refreshInterval    time.Duration
+	minRefreshInterval time.Duration
+	logger             *zap.Logger
+
+	mu        sync.Mutex
+	lastFetch time.Time
+	started   bool
+	stopOnce  sync.Once
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+// NewRemoteJWKS creates a remote key set for the given JWKS URL. It returns
+// ErrInvalidRefreshInterval if refreshInterval is not positive.
+func NewRemoteJWKS(url string, refreshInterval time.Duration, logger *zap.Logger) (*RemoteJWKS, error) {
+	if refreshInterval <= 0 {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidRefreshInterval, refreshInterval)
+	}
+	return &RemoteJWKS{
+		url:                url,
+		client:             &http.Client{Timeout: 10 * time.Second},
+		keyring:            NewKeyring(nil),
+		refreshInterval:    refreshInterval,
+		minRefreshInterval: 30 * time.Second,
+		logger:             logger,
+		stop:               make(chan struct{}),
+		done:               make(chan struct{}),
+	}, nil
+}
+
+// NewRemoteJWKSFromConfig creates a remote key set from JWT_JWKS_URL and
+// JWT_JWKS_REFRESH. The refresh interval defaults to five minutes.
+func NewRemoteJWKSFromConfig(cfg config.JWTConfig, logger *zap.Logger) (*RemoteJWKS, error) {
+	if cfg.JWKSURL == "" {
+		return nil, fmt.Errorf("JWKS URL is not configured")
+	}
+	refresh := 5 * time.Minute
+	if cfg.JWKSRefresh != "" {
+		d, err := time.ParseDuration(cfg.JWKSRefresh)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWKS refresh interval: %w", err)
+		}
+		refresh = d
+	}
+	return NewRemoteJWKS(cfg.JWKSURL, refresh, logger)
+}
+
+// Start performs the initial fetch and then refreshes the key set every
+// refresh interval until ctx is cancelled or Close is called. It returns
+// ErrJWKSAlreadyStarted if the background refresh is already running.
+func (r *RemoteJWKS) Start(ctx context.Context) error {
+	if r.isStarted() {
+		return ErrJWKSAlreadyStarted
+	}
+	if err := r.Refresh(ctx); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	if r.started {
+		r.mu.Unlock()
+		return ErrJWKSAlreadyStarted
+	}
+	r.started = true
+	r.mu.Unlock()
+
+	go func() {
+		defer close(r.done)
+		ticker := time.NewTicker(r.refreshInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-r.stop:
+				return
+			case <-ticker.C:
+				if err := r.Refresh(ctx); err != nil {
+					r.logger.Warn("failed to refresh JWKS, keeping cached keys",
+						zap.String("url", r.url),
+						zap.Error(err),
+					)
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// Close stops the background refresh and waits for it to exit.
+func (r *RemoteJWKS) Close() {
+	r.stopOnce.Do(func() { close(r.stop) })
+	if r.isStarted() {
+		<-r.done
+	}
+}
+
+// isStarted reports whether the background refresh has been started.
+func (r *RemoteJWKS) isStarted() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.started
+}
+
+// Refresh fetches the key set now and replaces the cached keys.
+func (r *RemoteJWKS) Refresh(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to build JWKS request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch JWKS from %s: %w", r.url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch JWKS from %s: status %d", r.url, resp.StatusCode)
+	}
+
+	var set JWKSet
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return fmt.Errorf("failed to decode JWKS: %w", err)
+	}
+
+	retired := make([]RetiredKey, 0, len(set.Keys))
+	for _, jwk := range set.Keys {
+		key, err := jwk.Key()
+		if err != nil {
+			r.logger.Warn("skipping unsupported JWK", zap.String("kid", jwk.KeyID), zap.Error(err))
+			continue
+		}
+		retired = append(retired, RetiredKey{Key: key})
+	}
+
+	r.keyring.Replace(nil, retired...)
+	r.mu.Lock()
+	r.lastFetch = time.Now()
+	r.mu.Unlock()
+
+	r.logger.Debug("JWKS refreshed", zap.String("url", r.url), zap.Int("keys", len(retired)))
+	return nil
+}
+
+// Lookup returns the cached key for kid, refreshing once if it is unknown.
+func (r *RemoteJWKS) Lookup(kid string) (*Key, bool) {
+	if key, ok := r.keyring.Lookup(kid); ok {
+		return key, true
+	}
+
+	// Claim the refresh slot up front so a burst of unknown kids fetches once.
+	r.mu.Lock()
+	stale := time.Since(r.lastFetch) >= r.minRefreshInterval
+	if stale {
+		r.lastFetch = time.Now()
+	}
+	r.mu.Unlock()
+	if !stale {
+		return nil, false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.client.Timeout)
+	defer cancel()
+	if err := r.Refresh(ctx); err != nil {
+		r.logger.Warn("failed to refresh JWKS for unknown kid",
+			zap.String("kid", kid),
+			zap.Error(err),
+		)
+		return nil, false
+	}
+	return r.keyring.Lookup(kid)
+}
+
+// Keys returns the cached verification keys.
+func (r *RemoteJWKS) Keys() []*Key {
+	return r.keyring.Keys()
+}
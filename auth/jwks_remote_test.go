@@ -0,0 +1,226 @@
+package auth
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// jwksServer publishes the signer's public keys over httptest and counts
+// how many times the key set was fetched.
+type jwksServer struct {
+	*httptest.Server
+	signer  *JWTManager
+	fetches atomic.Int32
+}
+
+func newJWKSServer(t *testing.T) *jwksServer {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	s := &jwksServer{
+		signer: NewJWTManagerWithKeys(newEd25519Key(t, "key-1"), nil, time.Minute, time.Hour),
+	}
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		s.fetches.Add(1)
+		c.Next()
+	})
+	RegisterJWKSRoute(r, s.signer)
+
+	s.Server = httptest.NewServer(r)
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *jwksServer) url() string {
+	return s.URL + JWKSPath
+}
+
+func newEd25519Key(t *testing.T, id string) *Key {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("ed25519.GenerateKey: %v", err)
+	}
+	return NewEd25519Key(id, priv)
+}
+
+func signAccessToken(t *testing.T, m *JWTManager) string {
+	t.Helper()
+	token, err := m.GenerateAccessToken(uuid.New(), "owner@example.com", RoleOwner)
+	if err != nil {
+		t.Fatalf("GenerateAccessToken: %v", err)
+	}
+	return token
+}
+
+func TestRemoteJWKSStartFetchesKeys(t *testing.T) {
+	srv := newJWKSServer(t)
+	remote := newRemoteJWKS(t, srv.url(), time.Hour)
+	if err := remote.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer remote.Close()
+
+	key, ok := remote.Lookup("key-1")
+	if !ok {
+		t.Fatal("key-1 not found after Start")
+	}
+	if key.CanSign() {
+		t.Fatal("remote key can sign, want verification only")
+	}
+
+	verifier := NewJWTVerifierFromSource(remote)
+	if _, err := verifier.ValidateAccessToken(signAccessToken(t, srv.signer)); err != nil {
+		t.Fatalf("ValidateAccessToken: %v", err)
+	}
+}
+
+func TestRemoteJWKSStartFailsOnBadStatus(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	remote := newRemoteJWKS(t, srv.URL, time.Hour)
+	if err := remote.Start(context.Background()); err == nil {
+		remote.Close()
+		t.Fatal("Start succeeded against a 404 endpoint")
+	}
+	remote.Close()
+}
+
+func TestRemoteJWKSBackgroundRefresh(t *testing.T) {
+	srv := newJWKSServer(t)
+	remote := newRemoteJWKS(t, srv.url(), 10*time.Millisecond)
+	remote.minRefreshInterval = time.Hour
+	if err := remote.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer remote.Close()
+
+	if err := srv.signer.RotateKey(newEd25519Key(t, "key-2")); err != nil {
+		t.Fatalf("RotateKey: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !hasKey(remote, "key-2") {
+		if time.Now().After(deadline) {
+			t.Fatal("background refresh did not pick up the rotated key")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if !hasKey(remote, "key-1") {
+		t.Fatal("retired key-1 was dropped while its tokens are still valid")
+	}
+}
+
+func TestRemoteJWKSRefreshesOnUnknownKeyID(t *testing.T) {
+	srv := newJWKSServer(t)
+	remote := newRemoteJWKS(t, srv.url(), time.Hour)
+	remote.minRefreshInterval = 0
+	if err := remote.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer remote.Close()
+
+	if err := srv.signer.RotateKey(newEd25519Key(t, "key-2")); err != nil {
+		t.Fatalf("RotateKey: %v", err)
+	}
+	fetches := srv.fetches.Load()
+
+	verifier := NewJWTVerifierFromSource(remote)
+	claims, err := verifier.ValidateAccessToken(signAccessToken(t, srv.signer))
+	if err != nil {
+		t.Fatalf("ValidateAccessToken with rotated key: %v", err)
+	}
+	if claims.Role != RoleOwner {
+		t.Fatalf("Role = %q, want %q", claims.Role, RoleOwner)
+	}
+	if got := srv.fetches.Load(); got != fetches+1 {
+		t.Fatalf("fetches = %d, want %d", got, fetches+1)
+	}
+}
+
+func TestRemoteJWKSThrottlesUnknownKeyID(t *testing.T) {
+	srv := newJWKSServer(t)
+	remote := newRemoteJWKS(t, srv.url(), time.Hour)
+	if err := remote.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer remote.Close()
+
+	fetches := srv.fetches.Load()
+	for i := 0; i < 5; i++ {
+		if _, ok := remote.Lookup("unknown"); ok {
+			t.Fatal("Lookup found an unknown kid")
+		}
+	}
+	if got := srv.fetches.Load(); got != fetches {
+		t.Fatalf("fetches = %d, want %d within the minimum refresh interval", got, fetches)
+	}
+}
+
+func TestRemoteJWKSRejectsInvalidRefreshInterval(t *testing.T) {
+	for _, d := range []time.Duration{0, -time.Second} {
+		if _, err := NewRemoteJWKS("http://localhost/jwks.json", d, zap.NewNop()); !errors.Is(err, ErrInvalidRefreshInterval) {
+			t.Fatalf("refresh interval %s: got %v, want ErrInvalidRefreshInterval", d, err)
+		}
+	}
+}
+
+func TestRemoteJWKSStartTwice(t *testing.T) {
+	srv := newJWKSServer(t)
+	remote := newRemoteJWKS(t, srv.url(), time.Hour)
+	if err := remote.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer remote.Close()
+
+	if err := remote.Start(context.Background()); !errors.Is(err, ErrJWKSAlreadyStarted) {
+		t.Fatalf("second Start: got %v, want ErrJWKSAlreadyStarted", err)
+	}
+}
+
+func TestRemoteJWKSCloseStopsRefresh(t *testing.T) {
+	srv := newJWKSServer(t)
+	remote := newRemoteJWKS(t, srv.url(), 5*time.Millisecond)
+	if err := remote.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	remote.Close()
+	remote.Close()
+
+	fetches := srv.fetches.Load()
+	time.Sleep(30 * time.Millisecond)
+	if got := srv.fetches.Load(); got != fetches {
+		t.Fatalf("fetches = %d after Close, want %d", got, fetches)
+	}
+}
+
+func newRemoteJWKS(t *testing.T, url string, refreshInterval time.Duration) *RemoteJWKS {
+	t.Helper()
+	remote, err := NewRemoteJWKS(url, refreshInterval, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewRemoteJWKS: %v", err)
+	}
+	return remote
+}
+
+func hasKey(src KeySource, kid string) bool {
+	for _, k := range src.Keys() {
+		if k.ID == kid {
+			return true
+		}
+	}
+	return false
+}
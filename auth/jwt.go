@@ -1,19 +1,25 @@
 package auth
 
 import (
+	"context"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/Kilat-Pet-Delivery/lib-common/config"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 )
 
-// TokenType distinguishes access tokens from refresh tokens.
+// TokenType distinguishes access, refresh and service tokens.
 type TokenType string
 
 const (
 	AccessToken  TokenType = "access"
 	RefreshToken TokenType = "refresh"
+	ServiceToken TokenType = "service"
+	// PurposeToken is a single-use token such as an email verification link.
+	PurposeToken TokenType = "purpose"
 )
 
 // UserRole represents the role of a user in the system.
@@ -24,6 +30,8 @@ const (
 	RoleRunner UserRole = "runner"
 	RoleAdmin  UserRole = "admin"
 	RoleShop   UserRole = "shop"
+	// RoleService is the role carried by service-principal tokens.
+	RoleService UserRole = "service"
 )
 
 // Claims represents the JWT payload.
@@ -33,90 +41,566 @@ type Claims struct {
 	Email     string    `json:"email"`
 	Role      UserRole  `json:"role"`
 	TokenType TokenType `json:"token_type"`
+	FamilyID  string    `json:"family_id,omitempty"`
+	// ServiceName identifies the calling service on service tokens.
+	ServiceName string `json:"service_name,omitempty"`
+	// MFAAt records when the user completed two-factor authentication.
+	MFAAt *jwt.NumericDate `json:"mfa_at,omitempty"`
+	// Actor is set on impersonation tokens and identifies the admin acting as UserID.
+	Actor *Actor `json:"act,omitempty"`
+	// Purpose restricts a purpose token to a single flow.
+	Purpose Purpose `json:"purpose,omitempty"`
+	// Scope is a space-separated list of OAuth2 scopes.
+	Scope string `json:"scope,omitempty"`
+	// Tenant scopes the token to one shop and the user's role in it.
+	Tenant *TenantClaim `json:"tenant,omitempty"`
+}
+
+// Scopes returns the token's OAuth2 scopes.
+func (c *Claims) Scopes() []string {
+	return strings.Fields(c.Scope)
+}
+
+// MFAVerified returns true if the token records a completed second factor.
+func (c *Claims) MFAVerified() bool {
+	return c.MFAAt != nil
+}
+
+// TokenOption adds optional claims to a generated access token.
+type TokenOption func(*Claims)
+
+// WithScopes sets the OAuth2 scopes carried by the token.
+func WithScopes(scopes ...string) TokenOption {
+	return func(c *Claims) {
+		c.Scope = strings.Join(scopes, " ")
+	}
+}
+
+// WithMFA records that the user completed two-factor authentication at the given time.
+func WithMFA(at time.Time) TokenOption {
+	return func(c *Claims) {
+		c.MFAAt = jwt.NewNumericDate(at)
+	}
+}
+
+// HasAudience returns true if the token's aud claim contains audience.
+func (c *Claims) HasAudience(audience string) bool {
+	for _, aud := range c.Audience {
+		if aud == audience {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultIssuer is the iss claim used when no issuer is configured.
+const defaultIssuer = "kilat-pet-runner"
+
+// KeySource supplies verification keys by key ID.
+type KeySource interface {
+	Lookup(kid string) (*Key, bool)
+	Keys() []*Key
 }
 
 // JWTManager handles JWT token generation and validation.
 type JWTManager struct {
-	secretKey      []byte
-	accessExpiry   time.Duration
-	refreshExpiry  time.Duration
+	keyring       *Keyring
+	keys          KeySource
+	accessExpiry  time.Duration
+	refreshExpiry time.Duration
+	revocations   RevocationStore
+	audiences     []string
+	serviceExpiry time.Duration
+	issuer        string
+	expectedIss   []string
+	expectedAud   []string
+	leeway        time.Duration
+	impersonation time.Duration
+	purposeExpiry map[Purpose]time.Duration
+	consumed      ConsumedTokenStore
+	opaque        OpaqueTokenStore
+}
+
+// Option configures optional JWTManager behaviour.
+type Option func(*JWTManager)
+
+// WithAudience sets the aud claim placed on user access and refresh tokens.
+func WithAudience(audiences ...string) Option {
+	return func(m *JWTManager) {
+		m.audiences = audiences
+	}
 }
 
-// NewJWTManager creates a new JWT manager.
-func NewJWTManager(secretKey string, accessExpiry, refreshExpiry time.Duration) *JWTManager {
-	return &JWTManager{
-		secretKey:     []byte(secretKey),
+// WithIssuer sets the iss claim placed on issued tokens.
+func WithIssuer(issuer string) Option {
+	return func(m *JWTManager) {
+		m.issuer = issuer
+	}
+}
+
+// WithExpectedIssuers makes validation reject tokens whose iss claim is not one of issuers.
+func WithExpectedIssuers(issuers ...string) Option {
+	return func(m *JWTManager) {
+		m.expectedIss = issuers
+	}
+}
+
+// WithExpectedAudiences makes validation reject tokens whose aud claim
+// contains none of audiences.
+func WithExpectedAudiences(audiences ...string) Option {
+	return func(m *JWTManager) {
+		m.expectedAud = audiences
+	}
+}
+
+// WithLeeway allows for clock drift between pods when checking exp, nbf and iat.
+func WithLeeway(leeway time.Duration) Option {
+	return func(m *JWTManager) {
+		m.leeway = leeway
+	}
+}
+
+// ConfigOptions converts the issuer, audience and leeway settings in cfg
+// into manager options. When JWT_EXPECTED_ISSUERS is unset, JWT_ISSUER is
+// also the expected issuer.
+func ConfigOptions(cfg config.JWTConfig) ([]Option, error) {
+	var opts []Option
+	if cfg.Issuer != "" {
+		opts = append(opts, WithIssuer(cfg.Issuer))
+	}
+	switch {
+	case len(cfg.ExpectedIssuers) > 0:
+		opts = append(opts, WithExpectedIssuers(cfg.ExpectedIssuers...))
+	case cfg.Issuer != "":
+		opts = append(opts, WithExpectedIssuers(cfg.Issuer))
+	}
+	if len(cfg.Audiences) > 0 {
+		opts = append(opts, WithAudience(cfg.Audiences...))
+	}
+	if len(cfg.ExpectedAudiences) > 0 {
+		opts = append(opts, WithExpectedAudiences(cfg.ExpectedAudiences...))
+	}
+	if cfg.Leeway != "" {
+		leeway, err := time.ParseDuration(cfg.Leeway)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWT leeway: %w", err)
+		}
+		opts = append(opts, WithLeeway(leeway))
+	}
+	return opts, nil
+}
+
+// WithServiceTokenExpiry sets the lifetime of service tokens.
+// It defaults to the access token expiry.
+func WithServiceTokenExpiry(expiry time.Duration) Option {
+	return func(m *JWTManager) {
+		m.serviceExpiry = expiry
+	}
+}
+
+// WithImpersonationMaxLifetime caps the lifetime of impersonation tokens.
+// It defaults to 15 minutes.
+func WithImpersonationMaxLifetime(lifetime time.Duration) Option {
+	return func(m *JWTManager) {
+		m.impersonation = lifetime
+	}
+}
+
+// WithRevocationStore makes token validation consult a revocation denylist.
+func WithRevocationStore(store RevocationStore) Option {
+	return func(m *JWTManager) {
+		m.revocations = store
+	}
+}
+
+// NewJWTManager creates a new JWT manager that signs with a shared HS256 secret.
+func NewJWTManager(secretKey string, accessExpiry, refreshExpiry time.Duration, opts ...Option) *JWTManager {
+	return NewJWTManagerWithKeys(NewHMACKey("", []byte(secretKey)), nil, accessExpiry, refreshExpiry, opts...)
+}
+
+// NewJWTManagerWithKeys creates a JWT manager that signs with signingKey and
+// validates against it plus any additional verification keys. Tokens carry
+// the signing key ID in their kid header.
+func NewJWTManagerWithKeys(signingKey *Key, verificationKeys []*Key, accessExpiry, refreshExpiry time.Duration, opts ...Option) *JWTManager {
+	retired := make([]RetiredKey, 0, len(verificationKeys))
+	for _, k := range verificationKeys {
+		retired = append(retired, RetiredKey{Key: k})
+	}
+	return NewJWTManagerWithKeyring(NewKeyring(signingKey, retired...), accessExpiry, refreshExpiry, opts...)
+}
+
+// NewJWTManagerWithKeyring creates a JWT manager backed by a rotatable keyring.
+func NewJWTManagerWithKeyring(keyring *Keyring, accessExpiry, refreshExpiry time.Duration, opts ...Option) *JWTManager {
+	m := &JWTManager{
+		keyring:       keyring,
+		keys:          keyring,
 		accessExpiry:  accessExpiry,
 		refreshExpiry: refreshExpiry,
+		serviceExpiry: accessExpiry,
+		issuer:        defaultIssuer,
+		impersonation: defaultImpersonationLifetime,
+	}
+	for _, opt := range opts {
+		opt(m)
 	}
+	return m
 }
 
-// GenerateAccessToken creates a short-lived access token.
-func (m *JWTManager) GenerateAccessToken(userID uuid.UUID, email string, role UserRole) (string, error) {
-	claims := Claims{
-		RegisteredClaims: jwt.RegisteredClaims{
-			ID:        uuid.New().String(),
-			Subject:   userID.String(),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(m.accessExpiry)),
-			Issuer:    "kilat-pet-runner",
-		},
-		UserID:    userID,
-		Email:     email,
-		Role:      role,
-		TokenType: AccessToken,
+// NewJWTManagerFromConfig creates a JWT manager from JWT configuration.
+func NewJWTManagerFromConfig(cfg config.JWTConfig, opts ...Option) (*JWTManager, error) {
+	accessExpiry, err := time.ParseDuration(cfg.AccessExpiry)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT access expiry: %w", err)
+	}
+	refreshExpiry, err := time.ParseDuration(cfg.RefreshExpiry)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT refresh expiry: %w", err)
 	}
+	keyring, err := NewKeyringFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	cfgOpts, err := ConfigOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return NewJWTManagerWithKeyring(keyring, accessExpiry, refreshExpiry, append(cfgOpts, opts...)...), nil
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(m.secretKey)
+// NewJWTVerifier creates a JWT manager that can only validate tokens.
+// Services that never mint tokens need nothing but the issuer's public keys.
+func NewJWTVerifier(keys ...*Key) *JWTManager {
+	return NewJWTManagerWithKeys(nil, keys, 0, 0)
+}
+
+// NewJWTVerifierFromSource creates a validate-only JWT manager whose
+// verification keys come from src, such as a RemoteJWKS.
+func NewJWTVerifierFromSource(src KeySource, opts ...Option) *JWTManager {
+	m := &JWTManager{keys: src, issuer: defaultIssuer, impersonation: defaultImpersonationLifetime}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Keyring returns the manager's keyring so keys can be rotated or reloaded at runtime.
+// It is nil for managers created with NewJWTVerifierFromSource.
+func (m *JWTManager) Keyring() *Keyring {
+	return m.keyring
+}
+
+// RotateKey makes next the signing key. The previous key keeps verifying
+// until every token it signed has expired. next needs a key ID other than
+// the active key's, which is "" for managers created with NewJWTManager.
+// It returns ErrNoKeyring for managers created with NewJWTVerifierFromSource.
+func (m *JWTManager) RotateKey(next *Key) error {
+	if m.keyring == nil {
+		return ErrNoKeyring
+	}
+	return m.keyring.Rotate(next, m.maxLifetime()+m.leeway)
+}
+
+// maxLifetime returns the longest lifetime of any token the manager issues.
+func (m *JWTManager) maxLifetime() time.Duration {
+	lifetime := m.refreshExpiry
+	if m.accessExpiry > lifetime {
+		lifetime = m.accessExpiry
+	}
+	if m.serviceExpiry > lifetime {
+		lifetime = m.serviceExpiry
+	}
+	for purpose := range defaultPurposeExpiry {
+		if expiry, _ := m.expiryFor(purpose); expiry > lifetime {
+			lifetime = expiry
+		}
+	}
+	for _, expiry := range m.purposeExpiry {
+		if expiry > lifetime {
+			lifetime = expiry
+		}
+	}
+	return lifetime
+}
+
+// PublicKeys returns the verification-only form of every asymmetric key.
+func (m *JWTManager) PublicKeys() []*Key {
+	all := m.keys.Keys()
+	keys := make([]*Key, 0, len(all))
+	for _, k := range all {
+		if !k.IsSymmetric() {
+			keys = append(keys, k.VerifyOnly())
+		}
+	}
+	return keys
+}
+
+// sign signs claims with the active signing key.
+func (m *JWTManager) sign(claims jwt.Claims) (string, error) {
+	if m.keyring == nil {
+		return "", ErrNoSigningKey
+	}
+	key := m.keyring.Active()
+	if key == nil {
+		return "", ErrNoSigningKey
+	}
+	return key.sign(claims)
+}
+
+// keyFunc selects the verification key by kid and rejects any algorithm that key does not allow.
+func (m *JWTManager) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := m.keys.Lookup(kid)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKeyID, kid)
+	}
+	if token.Method.Alg() != key.Algorithm {
+		return nil, fmt.Errorf("%w: unexpected signing method %v", ErrInvalidSignature, token.Header["alg"])
+	}
+	return key.public, nil
+}
+
+// GenerateAccessToken creates a short-lived access token.
+func (m *JWTManager) GenerateAccessToken(userID uuid.UUID, email string, role UserRole, opts ...TokenOption) (string, error) {
+	token, _, err := m.generateAccessToken(userID, email, role, "", opts...)
+	return token, err
 }
 
 // GenerateRefreshToken creates a long-lived refresh token.
 func (m *JWTManager) GenerateRefreshToken(userID uuid.UUID) (string, error) {
-	claims := Claims{
+	token, _, err := m.generateRefreshToken(userID, "")
+	return token, err
+}
+
+// GenerateServiceToken creates a service-principal token for calls between
+// services. The token is only accepted by receivers listed in audiences.
+func (m *JWTManager) GenerateServiceToken(serviceName string, audiences ...string) (string, error) {
+	if serviceName == "" {
+		return "", fmt.Errorf("service name is required")
+	}
+	if len(audiences) == 0 {
+		return "", fmt.Errorf("service tokens require at least one audience")
+	}
+
+	now := time.Now()
+	claims := &Claims{
 		RegisteredClaims: jwt.RegisteredClaims{
 			ID:        uuid.New().String(),
-			Subject:   userID.String(),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(m.refreshExpiry)),
-			Issuer:    "kilat-pet-runner",
+			Subject:   "service:" + serviceName,
+			Audience:  audiences,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(m.serviceExpiry)),
+			Issuer:    m.issuer,
 		},
-		UserID:    userID,
-		TokenType: RefreshToken,
+		Role:        RoleService,
+		TokenType:   ServiceToken,
+		ServiceName: serviceName,
 	}
+	return m.sign(claims)
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(m.secretKey)
+// generateAccessToken signs an access token, optionally bound to a refresh token family.
+func (m *JWTManager) generateAccessToken(userID uuid.UUID, email string, role UserRole, familyID string, opts ...TokenOption) (string, *Claims, error) {
+	claims := &Claims{
+		RegisteredClaims: m.registeredClaims(userID, m.accessExpiry),
+		UserID:           userID,
+		Email:            email,
+		Role:             role,
+		TokenType:        AccessToken,
+		FamilyID:         familyID,
+	}
+	for _, opt := range opts {
+		opt(claims)
+	}
+
+	token, err := m.sign(claims)
+	if err != nil {
+		return "", nil, err
+	}
+	return token, claims, nil
+}
+
+// generateRefreshToken signs a refresh token, optionally bound to a refresh token family.
+func (m *JWTManager) generateRefreshToken(userID uuid.UUID, familyID string) (string, *Claims, error) {
+	claims := &Claims{
+		RegisteredClaims: m.registeredClaims(userID, m.refreshExpiry),
+		UserID:           userID,
+		TokenType:        RefreshToken,
+		FamilyID:         familyID,
+	}
+
+	token, err := m.sign(claims)
+	if err != nil {
+		return "", nil, err
+	}
+	return token, claims, nil
+}
+
+// registeredClaims builds the standard claims for a new token.
+func (m *JWTManager) registeredClaims(userID uuid.UUID, expiry time.Duration) jwt.RegisteredClaims {
+	now := time.Now()
+	claims := jwt.RegisteredClaims{
+		ID:        uuid.New().String(),
+		Subject:   userID.String(),
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(expiry)),
+		Issuer:    m.issuer,
+	}
+	if len(m.audiences) > 0 {
+		claims.Audience = m.audiences
+	}
+	return claims
 }
 
 // ValidateToken parses and validates a JWT token.
 func (m *JWTManager) ValidateToken(tokenString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	return m.ValidateTokenContext(context.Background(), tokenString)
+}
+
+// ValidateTokenContext parses and validates a JWT or opaque token and
+// checks it against the revocation store, if one is configured.
+func (m *JWTManager) ValidateTokenContext(ctx context.Context, tokenString string) (*Claims, error) {
+	var claims *Claims
+	var err error
+	if IsOpaqueToken(tokenString) {
+		claims, err = m.validateOpaqueToken(ctx, tokenString)
+	} else {
+		claims, err = m.parseToken(tokenString)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if m.revocations != nil {
+		revoked, err := m.revocations.IsRevoked(ctx, claims)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check token revocation: %w", err)
 		}
-		return m.secretKey, nil
-	})
+		if revoked {
+			return nil, ErrTokenRevoked
+		}
+	}
+
+	return claims, nil
+}
+
+// parseToken verifies a JWT's signature and standard claims.
+func (m *JWTManager) parseToken(tokenString string) (*Claims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, m.keyFunc, m.parserOptions()...)
 	if err != nil {
-		return nil, fmt.Errorf("invalid token: %w", err)
+		return nil, classifyParseError(err)
 	}
 
 	claims, ok := token.Claims.(*Claims)
 	if !ok || !token.Valid {
-		return nil, fmt.Errorf("invalid token claims")
+		return nil, fmt.Errorf("%w: invalid token claims", ErrInvalidToken)
+	}
+	if len(m.expectedIss) > 0 && !containsString(m.expectedIss, claims.Issuer) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidIssuer, claims.Issuer)
+	}
+	if claims.Actor != nil && !m.allowsImpersonationLifetime(claims) {
+		return nil, fmt.Errorf("%w: impersonation token lifetime exceeds the maximum", ErrInvalidToken)
 	}
-
 	return claims, nil
 }
 
+// parserOptions returns the jwt parser options for the configured audiences and leeway.
+func (m *JWTManager) parserOptions() []jwt.ParserOption {
+	var opts []jwt.ParserOption
+	if len(m.expectedAud) > 0 {
+		opts = append(opts, jwt.WithAudience(m.expectedAud...))
+	}
+	if m.leeway > 0 {
+		opts = append(opts, jwt.WithLeeway(m.leeway))
+	}
+	return opts
+}
+
 // ValidateAccessToken validates an access token specifically.
 func (m *JWTManager) ValidateAccessToken(tokenString string) (*Claims, error) {
-	claims, err := m.ValidateToken(tokenString)
+	return m.ValidateAccessTokenContext(context.Background(), tokenString)
+}
+
+// ValidateAccessTokenContext validates an access token specifically.
+func (m *JWTManager) ValidateAccessTokenContext(ctx context.Context, tokenString string) (*Claims, error) {
+	claims, err := m.ValidateTokenContext(ctx, tokenString)
 	if err != nil {
 		return nil, err
 	}
 	if claims.TokenType != AccessToken {
-		return nil, fmt.Errorf("token is not an access token")
+		return nil, fmt.Errorf("%w: not an access token", ErrWrongTokenType)
 	}
 	return claims, nil
 }
+
+// ValidateRefreshToken validates a refresh token specifically.
+func (m *JWTManager) ValidateRefreshToken(tokenString string) (*Claims, error) {
+	return m.ValidateRefreshTokenContext(context.Background(), tokenString)
+}
+
+// ValidateRefreshTokenContext validates a refresh token specifically.
+func (m *JWTManager) ValidateRefreshTokenContext(ctx context.Context, tokenString string) (*Claims, error) {
+	claims, err := m.ValidateTokenContext(ctx, tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.TokenType != RefreshToken {
+		return nil, fmt.Errorf("%w: not a refresh token", ErrWrongTokenType)
+	}
+	return claims, nil
+}
+
+// ValidateServiceTokenContext validates a service token and requires its aud
+// claim to contain at least one of the given audiences.
+func (m *JWTManager) ValidateServiceTokenContext(ctx context.Context, tokenString string, audiences ...string) (*Claims, error) {
+	claims, err := m.ValidateTokenContext(ctx, tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.TokenType != ServiceToken {
+		return nil, fmt.Errorf("%w: not a service token", ErrWrongTokenType)
+	}
+	for _, aud := range audiences {
+		if claims.HasAudience(aud) {
+			return claims, nil
+		}
+	}
+	return nil, ErrInvalidAudience
+}
+
+// RevokeToken adds a single token to the revocation denylist until it expires.
+// The entry outlives exp by the validation leeway, during which the parser
+// still accepts the token.
+func (m *JWTManager) RevokeToken(ctx context.Context, claims *Claims) error {
+	if m.revocations == nil {
+		return ErrNoRevocationStore
+	}
+	expiresAt := time.Now().Add(m.maxLifetime())
+	if claims.ExpiresAt != nil {
+		expiresAt = claims.ExpiresAt.Time
+	}
+	return m.revocations.RevokeToken(ctx, claims.ID, expiresAt.Add(m.leeway))
+}
+
+// RevokeUserTokens revokes every token issued to a user before the given time.
+// Token iat claims only have second precision, so before is truncated to the
+// second: tokens issued earlier in the same second as before stay valid,
+// which keeps a token issued right after the revocation, such as the one
+// from a fresh login, from being revoked with them. The entry is dropped
+// once the last revoked token would have expired, leeway included.
+func (m *JWTManager) RevokeUserTokens(ctx context.Context, userID uuid.UUID, before time.Time) error {
+	if m.revocations == nil {
+		return ErrNoRevocationStore
+	}
+	before = before.Truncate(time.Second)
+	return m.revocations.RevokeUserTokensBefore(ctx, userID, before, before.Add(m.maxLifetime()+m.leeway))
+}
+
+// containsString reports whether s is in list.
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,250 @@
+package auth
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/config"
+)
+
+// RetiredKey is a key that no longer signs but still verifies until NotAfter.
+// A zero NotAfter means the key verifies indefinitely.
+type RetiredKey struct {
+	Key      *Key
+	NotAfter time.Time
+}
+
+// expired reports whether the retired key should no longer verify.
+func (r RetiredKey) expired(now time.Time) bool {
+	return !r.NotAfter.IsZero() && now.After(r.NotAfter)
+}
+
+// Keyring holds one active signing key and any number of retired keys.
+// It is safe for concurrent use and can be rotated or reloaded at runtime.
+type Keyring struct {
+	mu      sync.RWMutex
+	active  *Key
+	retired map[string]RetiredKey
+}
+
+// NewKeyring creates a keyring. active may be nil for verify-only keyrings.
+func NewKeyring(active *Key, retired ...RetiredKey) *Keyring {
+	r := &Keyring{}
+	r.Replace(active, retired...)
+	return r
+}
+
+// NewKeyringFromConfig builds a keyring from JWT configuration.
+func NewKeyringFromConfig(cfg config.JWTConfig) (*Keyring, error) {
+	active, retired, err := keysFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return NewKeyring(active, retired...), nil
+}
+
+// Active returns the current signing key, or nil if there is none.
+func (r *Keyring) Active() *Key {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.active
+}
+
+// Lookup returns the key for kid if it is active or an unexpired retired key.
+func (r *Keyring) Lookup(kid string) (*Key, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.active != nil && r.active.ID == kid {
+		return r.active, true
+	}
+	rk, ok := r.retired[kid]
+	if !ok || rk.expired(time.Now()) {
+		return nil, false
+	}
+	return rk.Key, true
+}
+
+// Keys returns the active key followed by all unexpired retired keys.
+func (r *Keyring) Keys() []*Key {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	now := time.Now()
+	keys := make([]*Key, 0, len(r.retired)+1)
+	if r.active != nil {
+		keys = append(keys, r.active)
+	}
+	for _, rk := range r.retired {
+		if !rk.expired(now) {
+			keys = append(keys, rk.Key)
+		}
+	}
+	return keys
+}
+
+// Rotate makes next the active key and retires the previous active key
+// until now+retireFor. retireFor should be at least the longest token lifetime.
+// next must have a different ID from the active key, otherwise the active key
+// could not be retired and its tokens would stop validating; it returns
+// ErrKeyIDInUse in that case.
+func (r *Keyring) Rotate(next *Key, retireFor time.Duration) error {
+	if next == nil || !next.CanSign() {
+		return ErrNoSigningKey
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.active != nil {
+		if r.active.ID == next.ID {
+			return fmt.Errorf("%w: %q", ErrKeyIDInUse, next.ID)
+		}
+		r.retired[r.active.ID] = RetiredKey{Key: r.active, NotAfter: time.Now().Add(retireFor)}
+	}
+	delete(r.retired, next.ID)
+	r.active = next
+	return nil
+}
+
+// Replace atomically swaps the whole keyring contents.
+func (r *Keyring) Replace(active *Key, retired ...RetiredKey) {
+	m := make(map[string]RetiredKey, len(retired))
+	for _, rk := range retired {
+		m[rk.Key.ID] = rk
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.active = active
+	r.retired = m
+}
+
+// Reload rebuilds the keyring from configuration. On error the current keys are kept.
+func (r *Keyring) Reload(cfg config.JWTConfig) error {
+	active, retired, err := keysFromConfig(cfg)
+	if err != nil {
+		return err
+	}
+	r.Replace(active, retired...)
+	return nil
+}
+
+// Prune drops retired keys past their NotAfter time.
+func (r *Keyring) Prune() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for kid, rk := range r.retired {
+		if rk.expired(now) {
+			delete(r.retired, kid)
+		}
+	}
+}
+
+// keysFromConfig loads the active and retired keys described by cfg.
+// Keys from PublicKeys are verify-only and never expire.
+func keysFromConfig(cfg config.JWTConfig) (*Key, []RetiredKey, error) {
+	var active *Key
+	var err error
+	switch {
+	case cfg.PrivateKeyFile != "":
+		active, err = LoadPrivateKeyFile(cfg.KeyID, cfg.PrivateKeyFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := deriveKeyID(active); err != nil {
+			return nil, nil, err
+		}
+	case cfg.Secret != "":
+		active = NewHMACKey(cfg.KeyID, []byte(cfg.Secret))
+	}
+
+	retired := make([]RetiredKey, 0, len(cfg.PublicKeys)+len(cfg.RetiredKeys))
+	for _, kc := range cfg.PublicKeys {
+		path, ok := strings.CutPrefix(kc.Source, "file:")
+		if !ok {
+			return nil, nil, fmt.Errorf("public key %q must be a file: source", kc.ID)
+		}
+		key, err := LoadPublicKeyFile(kc.ID, path)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := deriveKeyID(key); err != nil {
+			return nil, nil, err
+		}
+		retired = append(retired, RetiredKey{Key: key})
+	}
+	for _, kc := range cfg.RetiredKeys {
+		rk, err := retiredKeyFromConfig(kc)
+		if err != nil {
+			return nil, nil, err
+		}
+		retired = append(retired, rk)
+	}
+
+	if active == nil && len(retired) == 0 {
+		return nil, nil, fmt.Errorf("no JWT keys configured")
+	}
+	return active, retired, nil
+}
+
+// retiredKeyFromConfig loads a single retired key entry.
+func retiredKeyFromConfig(kc config.JWTKeyConfig) (RetiredKey, error) {
+	var notAfter time.Time
+	if kc.NotAfter != "" {
+		t, err := time.Parse(time.RFC3339, kc.NotAfter)
+		if err != nil {
+			return RetiredKey{}, fmt.Errorf("invalid not-after time for key %q: %w", kc.ID, err)
+		}
+		notAfter = t
+	}
+
+	var key *Key
+	var err error
+	switch {
+	case kc.Source == "":
+		return RetiredKey{}, fmt.Errorf("no key material for retired key %q", kc.ID)
+	case strings.HasPrefix(kc.Source, "base64:"):
+		secret, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(kc.Source, "base64:"))
+		if err != nil || len(secret) == 0 {
+			return RetiredKey{}, fmt.Errorf("invalid base64 secret for retired key %q", kc.ID)
+		}
+		key = NewHMACKey(kc.ID, secret)
+	case strings.HasPrefix(kc.Source, "file:"):
+		path := strings.TrimPrefix(kc.Source, "file:")
+		key, err = LoadPrivateKeyFile(kc.ID, path)
+		if err != nil {
+			key, err = LoadPublicKeyFile(kc.ID, path)
+		}
+		if err != nil {
+			return RetiredKey{}, err
+		}
+		key = key.VerifyOnly()
+		if err := deriveKeyID(key); err != nil {
+			return RetiredKey{}, err
+		}
+	default:
+		return RetiredKey{}, fmt.Errorf("retired key %q must use a file: or base64: source", kc.ID)
+	}
+
+	return RetiredKey{Key: key, NotAfter: notAfter}, nil
+}
+
+// deriveKeyID sets the RFC 7638 thumbprint as the ID of an asymmetric key
+// configured without one, so the tokens it signs still carry a kid.
+func deriveKeyID(key *Key) error {
+	if key.ID != "" || key.IsSymmetric() {
+		return nil
+	}
+	kid, err := key.Thumbprint()
+	if err != nil {
+		return fmt.Errorf("failed to derive key id: %w", err)
+	}
+	key.ID = kid
+	return nil
+}
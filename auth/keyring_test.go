@@ -0,0 +1,53 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestRotateKeyFromDefaultManager(t *testing.T) {
+	m := NewJWTManager("old-secret", time.Minute, time.Hour)
+	old, err := m.GenerateAccessToken(uuid.New(), "owner@example.com", RoleOwner)
+	if err != nil {
+		t.Fatalf("GenerateAccessToken: %v", err)
+	}
+
+	if err := m.RotateKey(NewHMACKey("", []byte("new-secret"))); !errors.Is(err, ErrKeyIDInUse) {
+		t.Fatalf("rotating to the active kid: got %v, want ErrKeyIDInUse", err)
+	}
+	if _, err := m.ValidateAccessToken(old); err != nil {
+		t.Fatalf("token signed before the rejected rotation: %v", err)
+	}
+
+	if err := m.RotateKey(NewHMACKey("2026-10", []byte("new-secret"))); err != nil {
+		t.Fatalf("RotateKey: %v", err)
+	}
+	if _, err := m.ValidateAccessToken(old); err != nil {
+		t.Fatalf("token signed with the retired key: %v", err)
+	}
+
+	fresh, err := m.GenerateAccessToken(uuid.New(), "owner@example.com", RoleOwner)
+	if err != nil {
+		t.Fatalf("GenerateAccessToken: %v", err)
+	}
+	if _, err := m.ValidateAccessToken(fresh); err != nil {
+		t.Fatalf("token signed with the new key: %v", err)
+	}
+	if got := m.Keyring().Active().ID; got != "2026-10" {
+		t.Fatalf("active kid = %q, want 2026-10", got)
+	}
+}
+
+func TestRotateKeyRejectsNil(t *testing.T) {
+	m := NewJWTManager("secret", time.Minute, time.Hour)
+	if err := m.RotateKey(nil); !errors.Is(err, ErrNoSigningKey) {
+		t.Fatalf("RotateKey(nil): got %v, want ErrNoSigningKey", err)
+	}
+	verifier := NewJWTVerifierFromSource(NewKeyring(nil))
+	if err := verifier.RotateKey(NewHMACKey("k", []byte("s"))); !errors.Is(err, ErrNoKeyring) {
+		t.Fatalf("RotateKey on a source verifier: got %v, want ErrNoKeyring", err)
+	}
+}
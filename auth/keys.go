@@ -0,0 +1,245 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Supported JWT signing algorithms.
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgES256 = "ES256"
+	AlgES384 = "ES384"
+	AlgES512 = "ES512"
+	AlgEdDSA = "EdDSA"
+)
+
+// Key errors.
+var (
+	ErrNoSigningKey       = errors.New("no signing key configured")
+	ErrNoKeyring          = errors.New("JWT manager has no keyring")
+	ErrUnknownKeyID       = errors.New("unknown key id")
+	ErrUnsupportedKeyType = errors.New("unsupported key type")
+	ErrMissingKeyID       = errors.New("asymmetric signing keys require a key id")
+	ErrKeyIDInUse         = errors.New("key id is already the active key")
+)
+
+// Key is a named JWT key. A key built from a private key or HMAC secret can
+// sign and verify; a key built from a public key can only verify.
+type Key struct {
+	ID        string
+	Algorithm string
+	private   interface{}
+	public    interface{}
+}
+
+// NewHMACKey creates an HS256 key from a shared secret.
+func NewHMACKey(id string, secret []byte) *Key {
+	return &Key{ID: id, Algorithm: AlgHS256, private: secret, public: secret}
+}
+
+// NewRSAKey creates an RS256 signing key.
+func NewRSAKey(id string, priv *rsa.PrivateKey) *Key {
+	return &Key{ID: id, Algorithm: AlgRS256, private: priv, public: &priv.PublicKey}
+}
+
+// NewRSAPublicKey creates an RS256 verification-only key.
+func NewRSAPublicKey(id string, pub *rsa.PublicKey) *Key {
+	return &Key{ID: id, Algorithm: AlgRS256, public: pub}
+}
+
+// NewECDSAKey creates an ECDSA signing key. The algorithm follows the curve
+// (P-256 → ES256, P-384 → ES384, P-521 → ES512).
+func NewECDSAKey(id string, priv *ecdsa.PrivateKey) (*Key, error) {
+	alg, err := ecdsaAlgorithm(priv.Curve)
+	if err != nil {
+		return nil, err
+	}
+	return &Key{ID: id, Algorithm: alg, private: priv, public: &priv.PublicKey}, nil
+}
+
+// NewECDSAPublicKey creates an ECDSA verification-only key.
+func NewECDSAPublicKey(id string, pub *ecdsa.PublicKey) (*Key, error) {
+	alg, err := ecdsaAlgorithm(pub.Curve)
+	if err != nil {
+		return nil, err
+	}
+	return &Key{ID: id, Algorithm: alg, public: pub}, nil
+}
+
+// NewEd25519Key creates an EdDSA signing key.
+func NewEd25519Key(id string, priv ed25519.PrivateKey) *Key {
+	return &Key{ID: id, Algorithm: AlgEdDSA, private: priv, public: priv.Public()}
+}
+
+// NewEd25519PublicKey creates an EdDSA verification-only key.
+func NewEd25519PublicKey(id string, pub ed25519.PublicKey) *Key {
+	return &Key{ID: id, Algorithm: AlgEdDSA, public: pub}
+}
+
+// NewSigningKey creates a signing key from an RSA, ECDSA or Ed25519 private key.
+func NewSigningKey(id string, priv crypto.PrivateKey) (*Key, error) {
+	switch k := priv.(type) {
+	case *rsa.PrivateKey:
+		return NewRSAKey(id, k), nil
+	case *ecdsa.PrivateKey:
+		return NewECDSAKey(id, k)
+	case ed25519.PrivateKey:
+		return NewEd25519Key(id, k), nil
+	default:
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedKeyType, priv)
+	}
+}
+
+// NewVerificationKey creates a verification-only key from an RSA, ECDSA or Ed25519 public key.
+func NewVerificationKey(id string, pub crypto.PublicKey) (*Key, error) {
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		return NewRSAPublicKey(id, k), nil
+	case *ecdsa.PublicKey:
+		return NewECDSAPublicKey(id, k)
+	case ed25519.PublicKey:
+		return NewEd25519PublicKey(id, k), nil
+	default:
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedKeyType, pub)
+	}
+}
+
+// ParsePrivateKeyPEM parses a PEM-encoded PKCS#8, PKCS#1 or SEC 1 private key.
+func ParsePrivateKeyPEM(id string, data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode PEM private key")
+	}
+
+	var priv crypto.PrivateKey
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		priv, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		priv, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		priv, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	return NewSigningKey(id, priv)
+}
+
+// ParsePublicKeyPEM parses a PEM-encoded PKIX public key or certificate.
+func ParsePublicKeyPEM(id string, data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode PEM public key")
+	}
+
+	var pub crypto.PublicKey
+	var err error
+	switch block.Type {
+	case "CERTIFICATE":
+		var cert *x509.Certificate
+		cert, err = x509.ParseCertificate(block.Bytes)
+		if err == nil {
+			pub = cert.PublicKey
+		}
+	case "RSA PUBLIC KEY":
+		pub, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		pub, err = x509.ParsePKIXPublicKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+	return NewVerificationKey(id, pub)
+}
+
+// LoadPrivateKeyFile reads and parses a PEM private key file.
+func LoadPrivateKeyFile(id, path string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private key %s: %w", path, err)
+	}
+	return ParsePrivateKeyPEM(id, data)
+}
+
+// LoadPublicKeyFile reads and parses a PEM public key file.
+func LoadPublicKeyFile(id, path string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read public key %s: %w", path, err)
+	}
+	return ParsePublicKeyPEM(id, data)
+}
+
+// CanSign returns true if the key holds private key material.
+func (k *Key) CanSign() bool {
+	return k.private != nil
+}
+
+// IsSymmetric returns true for HMAC keys, which must never be published.
+func (k *Key) IsSymmetric() bool {
+	return k.Algorithm == AlgHS256
+}
+
+// PublicKey returns the public half of an asymmetric key, or nil for HMAC keys.
+func (k *Key) PublicKey() crypto.PublicKey {
+	if k.IsSymmetric() {
+		return nil
+	}
+	return k.public
+}
+
+// VerifyOnly returns a copy of the key without private material.
+func (k *Key) VerifyOnly() *Key {
+	if k.IsSymmetric() {
+		return k
+	}
+	return &Key{ID: k.ID, Algorithm: k.Algorithm, public: k.public}
+}
+
+// signingMethod returns the jwt signing method for the key algorithm.
+func (k *Key) signingMethod() jwt.SigningMethod {
+	return jwt.GetSigningMethod(k.Algorithm)
+}
+
+// sign signs the claims and stamps the key ID into the token header.
+// Asymmetric keys must have an ID so verifiers can select them by kid.
+func (k *Key) sign(claims jwt.Claims) (string, error) {
+	if !k.CanSign() {
+		return "", ErrNoSigningKey
+	}
+	if k.ID == "" && !k.IsSymmetric() {
+		return "", ErrMissingKeyID
+	}
+	token := jwt.NewWithClaims(k.signingMethod(), claims)
+	if k.ID != "" {
+		token.Header["kid"] = k.ID
+	}
+	return token.SignedString(k.private)
+}
+
+func ecdsaAlgorithm(curve elliptic.Curve) (string, error) {
+	switch curve {
+	case elliptic.P256():
+		return AlgES256, nil
+	case elliptic.P384():
+		return AlgES384, nil
+	case elliptic.P521():
+		return AlgES512, nil
+	default:
+		return "", fmt.Errorf("%w: ECDSA curve %s", ErrUnsupportedKeyType, curve.Params().Name)
+	}
+}
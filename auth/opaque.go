@@ -0,0 +1,140 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// Opaque token errors.
+var (
+	ErrOpaqueTokenNotFound = errors.New("opaque token not found")
+	ErrNoOpaqueTokenStore  = errors.New("no opaque token store configured")
+)
+
+// opaqueTokenPrefix marks reference tokens so they can be told apart from JWTs.
+const opaqueTokenPrefix = "kpo_"
+
+// OpaqueToken is the stored record behind an opaque reference token. Only a
+// SHA-256 hash of the token is kept.
+type OpaqueToken struct {
+	ID        string
+	Hash      string
+	UserID    uuid.UUID
+	Email     string
+	Role      UserRole
+	Scopes    []string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+	RevokedAt *time.Time
+}
+
+// OpaqueTokenStore persists opaque reference tokens.
+type OpaqueTokenStore interface {
+	// Create stores a new token.
+	Create(ctx context.Context, token *OpaqueToken) error
+	// GetByHash returns the token with the given hash or ErrOpaqueTokenNotFound.
+	GetByHash(ctx context.Context, hash string) (*OpaqueToken, error)
+	// Revoke revokes the token with the given hash.
+	Revoke(ctx context.Context, hash string) error
+}
+
+// WithOpaqueTokenStore enables opaque reference tokens backed by store.
+// ValidateToken then accepts both JWTs and opaque tokens.
+func WithOpaqueTokenStore(store OpaqueTokenStore) Option {
+	return func(m *JWTManager) {
+		m.opaque = store
+	}
+}
+
+// IsOpaqueToken returns true if the token is an opaque reference token rather than a JWT.
+func IsOpaqueToken(token string) bool {
+	return strings.HasPrefix(token, opaqueTokenPrefix)
+}
+
+// GenerateOpaqueToken creates an opaque access token for partners that
+// cannot parse JWTs. It lives as long as a JWT access token.
+func (m *JWTManager) GenerateOpaqueToken(ctx context.Context, userID uuid.UUID, email string, role UserRole, scopes ...string) (string, error) {
+	if m.opaque == nil {
+		return "", ErrNoOpaqueTokenStore
+	}
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate opaque token: %w", err)
+	}
+	token := opaqueTokenPrefix + base64.RawURLEncoding.EncodeToString(raw)
+
+	now := time.Now().UTC()
+	record := &OpaqueToken{
+		ID:        uuid.New().String(),
+		Hash:      hashOpaqueToken(token),
+		UserID:    userID,
+		Email:     email,
+		Role:      role,
+		Scopes:    scopes,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(m.accessExpiry),
+	}
+	if err := m.opaque.Create(ctx, record); err != nil {
+		return "", fmt.Errorf("failed to store opaque token: %w", err)
+	}
+	return token, nil
+}
+
+// RevokeOpaqueToken revokes an opaque token immediately.
+func (m *JWTManager) RevokeOpaqueToken(ctx context.Context, token string) error {
+	if m.opaque == nil {
+		return ErrNoOpaqueTokenStore
+	}
+	return m.opaque.Revoke(ctx, hashOpaqueToken(token))
+}
+
+// validateOpaqueToken looks up an opaque token and returns equivalent access token claims.
+func (m *JWTManager) validateOpaqueToken(ctx context.Context, token string) (*Claims, error) {
+	if m.opaque == nil {
+		return nil, fmt.Errorf("%w: opaque tokens are not enabled", ErrTokenMalformed)
+	}
+	record, err := m.opaque.GetByHash(ctx, hashOpaqueToken(token))
+	if errors.Is(err, ErrOpaqueTokenNotFound) {
+		return nil, fmt.Errorf("%w: unknown opaque token", ErrInvalidToken)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up opaque token: %w", err)
+	}
+	if record.RevokedAt != nil {
+		return nil, ErrTokenRevoked
+	}
+	if time.Now().After(record.ExpiresAt.Add(m.leeway)) {
+		return nil, ErrTokenExpired
+	}
+
+	return &Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        record.ID,
+			Subject:   record.UserID.String(),
+			IssuedAt:  jwt.NewNumericDate(record.IssuedAt),
+			ExpiresAt: jwt.NewNumericDate(record.ExpiresAt),
+			Issuer:    m.issuer,
+		},
+		UserID:    record.UserID,
+		Email:     record.Email,
+		Role:      record.Role,
+		TokenType: AccessToken,
+		Scope:     strings.Join(record.Scopes, " "),
+	}, nil
+}
+
+// hashOpaqueToken returns the hex SHA-256 of a token for storage.
+func hashOpaqueToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryOpaqueTokenStore is an in-process OpaqueTokenStore.
+type MemoryOpaqueTokenStore struct {
+	mu     sync.RWMutex
+	tokens map[string]*OpaqueToken
+}
+
+// NewMemoryOpaqueTokenStore creates an empty in-memory opaque token store.
+func NewMemoryOpaqueTokenStore() *MemoryOpaqueTokenStore {
+	return &MemoryOpaqueTokenStore{tokens: make(map[string]*OpaqueToken)}
+}
+
+// Create stores a new token.
+func (s *MemoryOpaqueTokenStore) Create(_ context.Context, token *OpaqueToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := *token
+	s.tokens[token.Hash] = &t
+	return nil
+}
+
+// GetByHash returns the token with the given hash.
+func (s *MemoryOpaqueTokenStore) GetByHash(_ context.Context, hash string) (*OpaqueToken, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	t, ok := s.tokens[hash]
+	if !ok {
+		return nil, ErrOpaqueTokenNotFound
+	}
+	out := *t
+	return &out, nil
+}
+
+// Revoke revokes the token with the given hash.
+func (s *MemoryOpaqueTokenStore) Revoke(_ context.Context, hash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.tokens[hash]
+	if !ok {
+		return ErrOpaqueTokenNotFound
+	}
+	if t.RevokedAt == nil {
+		now := time.Now().UTC()
+		t.RevokedAt = &now
+	}
+	return nil
+}
+
+// DeleteExpired removes tokens that have expired.
+func (s *MemoryOpaqueTokenStore) DeleteExpired(_ context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	var n int64
+	for hash, t := range s.tokens {
+		if !now.Before(t.ExpiresAt) {
+			delete(s.tokens, hash)
+			n++
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// opaqueTokenModel is the GORM model for the opaque_tokens table.
+type opaqueTokenModel struct {
+	Hash      string    `gorm:"primaryKey"`
+	ID        string    `gorm:"column:jti;not null;uniqueIndex"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;index"`
+	Email     string    `gorm:"not null;default:''"`
+	Role      string    `gorm:"not null"`
+	Scopes    string    `gorm:"not null;default:''"`
+	IssuedAt  time.Time `gorm:"not null"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+	RevokedAt *time.Time
+}
+
+// TableName sets the table name for GORM.
+func (opaqueTokenModel) TableName() string { return "opaque_tokens" }
+
+func (m *opaqueTokenModel) toOpaqueToken() *OpaqueToken {
+	return &OpaqueToken{
+		ID:        m.ID,
+		Hash:      m.Hash,
+		UserID:    m.UserID,
+		Email:     m.Email,
+		Role:      UserRole(m.Role),
+		Scopes:    strings.Fields(m.Scopes),
+		IssuedAt:  m.IssuedAt,
+		ExpiresAt: m.ExpiresAt,
+		RevokedAt: m.RevokedAt,
+	}
+}
+
+// PostgresOpaqueTokenStore is a GORM-backed OpaqueTokenStore.
+type PostgresOpaqueTokenStore struct {
+	db *gorm.DB
+}
+
+// NewPostgresOpaqueTokenStore creates a Postgres opaque token store.
+func NewPostgresOpaqueTokenStore(db *gorm.DB) *PostgresOpaqueTokenStore {
+	return &PostgresOpaqueTokenStore{db: db}
+}
+
+// AutoMigrate creates or updates the opaque_tokens table.
+func (s *PostgresOpaqueTokenStore) AutoMigrate() error {
+	return s.db.AutoMigrate(&opaqueTokenModel{})
+}
+
+// Create stores a new token.
+func (s *PostgresOpaqueTokenStore) Create(ctx context.Context, token *OpaqueToken) error {
+	model := opaqueTokenModel{
+		Hash:      token.Hash,
+		ID:        token.ID,
+		UserID:    token.UserID,
+		Email:     token.Email,
+		Role:      string(token.Role),
+		Scopes:    strings.Join(token.Scopes, " "),
+		IssuedAt:  token.IssuedAt,
+		ExpiresAt: token.ExpiresAt,
+	}
+	if err := s.db.WithContext(ctx).Create(&model).Error; err != nil {
+		return fmt.Errorf("failed to create opaque token: %w", err)
+	}
+	return nil
+}
+
+// GetByHash returns the token with the given hash.
+func (s *PostgresOpaqueTokenStore) GetByHash(ctx context.Context, hash string) (*OpaqueToken, error) {
+	var model opaqueTokenModel
+	err := s.db.WithContext(ctx).Where("hash = ?", hash).First(&model).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrOpaqueTokenNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get opaque token: %w", err)
+	}
+	return model.toOpaqueToken(), nil
+}
+
+// Revoke revokes the token with the given hash.
+func (s *PostgresOpaqueTokenStore) Revoke(ctx context.Context, hash string) error {
+	result := s.db.WithContext(ctx).Model(&opaqueTokenModel{}).
+		Where("hash = ? AND revoked_at IS NULL", hash).
+		Update("revoked_at", time.Now().UTC())
+	if result.Error != nil {
+		return fmt.Errorf("failed to revoke opaque token: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		if _, err := s.GetByHash(ctx, hash); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteExpired removes tokens that have expired.
+func (s *PostgresOpaqueTokenStore) DeleteExpired(ctx context.Context) (int64, error) {
+	result := s.db.WithContext(ctx).Where("expires_at < ?", time.Now().UTC()).Delete(&opaqueTokenModel{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete expired opaque tokens: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,144 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/domain"
+	"github.com/google/uuid"
+)
+
+// Principal is the authenticated caller an authorization decision is made for.
+type Principal struct {
+	UserID uuid.UUID
+	Role   UserRole
+}
+
+// OwnershipRule grants access to a resource when Allows returns true.
+type OwnershipRule[T any] struct {
+	Name   string
+	Allows func(p Principal, resource *T) bool
+}
+
+// OwnedBy grants access to callers with the given role whose user ID matches
+// the one returned by ownerID, e.g. a pet owner reading their own booking.
+// A nil owner ID never matches, so unassigned resources stay private.
+func OwnedBy[T any](role UserRole, ownerID func(resource *T) uuid.UUID) OwnershipRule[T] {
+	return OwnershipRule[T]{
+		Name: fmt.Sprintf("owned_by_%s", role),
+		Allows: func(p Principal, resource *T) bool {
+			id := ownerID(resource)
+			return p.Role == role && id != uuid.Nil && id == p.UserID
+		},
+	}
+}
+
+// AllowRole grants access to every caller with the given role.
+func AllowRole[T any](role UserRole) OwnershipRule[T] {
+	return OwnershipRule[T]{
+		Name:   fmt.Sprintf("role_%s", role),
+		Allows: func(p Principal, _ *T) bool { return p.Role == role },
+	}
+}
+
+// Authorizer evaluates ownership rules against loaded aggregates. Callers
+// with a bypass role (admin by default) are always allowed. Every decision
+// is sent to the auditor, if one is set.
+type Authorizer[T any] struct {
+	resource    string
+	rules       []OwnershipRule[T]
+	bypassRoles []UserRole
+	auditor     Auditor
+}
+
+// NewAuthorizer creates an authorizer for the named resource type.
+func NewAuthorizer[T any](resource string, rules ...OwnershipRule[T]) *Authorizer[T] {
+	return &Authorizer[T]{
+		resource:    resource,
+		rules:       rules,
+		bypassRoles: []UserRole{RoleAdmin},
+	}
+}
+
+// WithAuditor sets the auditor that records each decision.
+func (a *Authorizer[T]) WithAuditor(auditor Auditor) *Authorizer[T] {
+	a.auditor = auditor
+	return a
+}
+
+// WithBypassRoles replaces the roles that skip ownership checks.
+func (a *Authorizer[T]) WithBypassRoles(roles ...UserRole) *Authorizer[T] {
+	a.bypassRoles = roles
+	return a
+}
+
+// Authorize checks whether the principal may access resource. It returns a
+// forbidden DomainError when no rule matches.
+func (a *Authorizer[T]) Authorize(ctx context.Context, p Principal, resourceID uuid.UUID, resource *T) error {
+	allowed, reason := a.evaluate(p, resource)
+	a.audit(ctx, p, resourceID, allowed, reason)
+	if !allowed {
+		return domain.NewForbiddenError(fmt.Sprintf("not allowed to access this %s", a.resource))
+	}
+	return nil
+}
+
+// Load fetches the aggregate from the repository and authorizes access to it.
+func (a *Authorizer[T]) Load(ctx context.Context, repo domain.Repository[T], p Principal, id uuid.UUID) (*T, error) {
+	resource, err := repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.Authorize(ctx, p, id, resource); err != nil {
+		return nil, err
+	}
+	return resource, nil
+}
+
+// Filter returns the subset of resources the principal may access, e.g. for
+// list endpoints. Denied items are audited but not reported as errors.
+func (a *Authorizer[T]) Filter(ctx context.Context, p Principal, resources []T, resourceID func(*T) uuid.UUID) []T {
+	allowed := make([]T, 0, len(resources))
+	for i := range resources {
+		if a.Authorize(ctx, p, resourceID(&resources[i]), &resources[i]) == nil {
+			allowed = append(allowed, resources[i])
+		}
+	}
+	return allowed
+}
+
+// evaluate returns whether access is allowed and the reason for the decision.
+func (a *Authorizer[T]) evaluate(p Principal, resource *T) (bool, string) {
+	for _, role := range a.bypassRoles {
+		if p.Role == role {
+			return true, fmt.Sprintf("bypass_%s", role)
+		}
+	}
+	if resource == nil {
+		return false, "resource not loaded"
+	}
+	for _, rule := range a.rules {
+		if rule.Allows(p, resource) {
+			return true, rule.Name
+		}
+	}
+	return false, "no matching ownership rule"
+}
+
+// audit records the decision with the configured auditor.
+func (a *Authorizer[T]) audit(ctx context.Context, p Principal, resourceID uuid.UUID, allowed bool, reason string) {
+	if a.auditor == nil {
+		return
+	}
+	a.auditor.Audit(ctx, AuditEvent{
+		Type:       AuditAccessDecision,
+		ActorID:    p.UserID,
+		ActorRole:  p.Role,
+		Resource:   a.resource,
+		ResourceID: resourceID.String(),
+		Allowed:    allowed,
+		Reason:     reason,
+		OccurredAt: time.Now().UTC(),
+	})
+}
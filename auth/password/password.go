@@ -0,0 +1,206 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Password errors.
+var (
+	ErrMismatch      = errors.New("password does not match")
+	ErrInvalidHash   = errors.New("invalid password hash")
+	ErrUnknownScheme = errors.New("unknown password hash scheme")
+)
+
+// Params are the argon2id cost parameters.
+type Params struct {
+	Memory      uint32 // KiB
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultParams returns argon2id parameters suitable for interactive logins.
+func DefaultParams() Params {
+	return Params{
+		Memory:      64 * 1024,
+		Iterations:  3,
+		Parallelism: 2,
+		SaltLength:  16,
+		KeyLength:   32,
+	}
+}
+
+// Hasher hashes passwords with argon2id and verifies argon2id and legacy bcrypt hashes.
+type Hasher struct {
+	params Params
+	policy Policy
+}
+
+// NewHasher creates a hasher with the given parameters and strength policy.
+func NewHasher(params Params, policy Policy) *Hasher {
+	return &Hasher{params: params, policy: policy}
+}
+
+// NewDefaultHasher creates a hasher with the default parameters and policy.
+func NewDefaultHasher() *Hasher {
+	return NewHasher(DefaultParams(), DefaultPolicy())
+}
+
+// Policy returns the hasher's strength policy.
+func (h *Hasher) Policy() Policy {
+	return h.policy
+}
+
+// Hash checks the password against the strength policy and returns an
+// encoded argon2id hash in PHC string format.
+func (h *Hasher) Hash(password string) (string, error) {
+	if err := h.policy.Validate(password); err != nil {
+		return "", err
+	}
+	return h.hash(password)
+}
+
+// hash encodes the password without checking the policy.
+func (h *Hasher) hash(password string) (string, error) {
+	salt := make([]byte, h.params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	p := h.params
+	key := argon2.IDKey([]byte(password), salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, p.Memory, p.Iterations, p.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// Verify checks password against an encoded argon2id or bcrypt hash. It
+// returns ErrMismatch when the password is wrong. needsRehash is true when
+// the password is correct but the hash should be replaced, because it uses
+// bcrypt or outdated argon2id parameters.
+func (h *Hasher) Verify(password, encoded string) (needsRehash bool, err error) {
+	switch {
+	case strings.HasPrefix(encoded, "$argon2id$"):
+		params, salt, key, err := decodeArgon2id(encoded)
+		if err != nil {
+			return false, err
+		}
+		other := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+		if subtle.ConstantTimeCompare(key, other) != 1 {
+			return false, ErrMismatch
+		}
+		return params != h.params, nil
+	case isBcrypt(encoded):
+		err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, ErrMismatch
+		}
+		if err != nil {
+			return false, fmt.Errorf("%w: %v", ErrInvalidHash, err)
+		}
+		return true, nil
+	default:
+		return false, ErrUnknownScheme
+	}
+}
+
+// VerifyAndUpgrade verifies the password and, when the stored hash is
+// outdated, returns a fresh argon2id hash for the caller to persist. The
+// new hash is empty when no upgrade is needed.
+func (h *Hasher) VerifyAndUpgrade(password, encoded string) (string, error) {
+	needsRehash, err := h.Verify(password, encoded)
+	if err != nil || !needsRehash {
+		return "", err
+	}
+	// The password already verified, so it is rehashed without re-checking
+	// the policy; a policy change must not lock existing users out.
+	return h.hash(password)
+}
+
+// NeedsRehash reports whether the encoded hash uses bcrypt or argon2id
+// parameters other than the hasher's current ones.
+func (h *Hasher) NeedsRehash(encoded string) bool {
+	if !strings.HasPrefix(encoded, "$argon2id$") {
+		return true
+	}
+	params, _, _, err := decodeArgon2id(encoded)
+	return err != nil || params != h.params
+}
+
+// Bounds on the argon2id parameters accepted from stored hashes. Zero
+// iterations or parallelism make argon2 panic, an empty key would match any
+// password and huge costs would let one corrupted row exhaust the server.
+const (
+	maxArgon2Memory     = 4 * 1024 * 1024 // KiB
+	maxArgon2Iterations = 64
+	minArgon2SaltLength = 8
+	minArgon2KeyLength  = 16
+)
+
+// decodeArgon2id parses a PHC-format argon2id hash.
+func decodeArgon2id(encoded string) (Params, []byte, []byte, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 {
+		return Params{}, nil, nil, ErrInvalidHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return Params{}, nil, nil, fmt.Errorf("%w: unsupported argon2 version", ErrInvalidHash)
+	}
+
+	var p Params
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.Memory, &p.Iterations, &p.Parallelism); err != nil {
+		return Params{}, nil, nil, fmt.Errorf("%w: %v", ErrInvalidHash, err)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return Params{}, nil, nil, fmt.Errorf("%w: %v", ErrInvalidHash, err)
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return Params{}, nil, nil, fmt.Errorf("%w: %v", ErrInvalidHash, err)
+	}
+	p.SaltLength = uint32(len(salt))
+	p.KeyLength = uint32(len(key))
+	if err := p.check(); err != nil {
+		return Params{}, nil, nil, err
+	}
+	return p, salt, key, nil
+}
+
+// check rejects parameters outside the bounds a stored hash may use.
+func (p Params) check() error {
+	switch {
+	case p.Iterations == 0 || p.Iterations > maxArgon2Iterations:
+		return fmt.Errorf("%w: iterations %d out of range", ErrInvalidHash, p.Iterations)
+	case p.Parallelism == 0:
+		return fmt.Errorf("%w: parallelism must be positive", ErrInvalidHash)
+	case p.Memory < 8*uint32(p.Parallelism) || p.Memory > maxArgon2Memory:
+		return fmt.Errorf("%w: memory %d KiB out of range", ErrInvalidHash, p.Memory)
+	case p.SaltLength < minArgon2SaltLength:
+		return fmt.Errorf("%w: salt is too short", ErrInvalidHash)
+	case p.KeyLength < minArgon2KeyLength:
+		return fmt.Errorf("%w: key is too short", ErrInvalidHash)
+	}
+	return nil
+}
+
+// isBcrypt reports whether the hash uses one of the bcrypt prefixes.
+func isBcrypt(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") ||
+		strings.HasPrefix(encoded, "$2b$") ||
+		strings.HasPrefix(encoded, "$2y$")
+}
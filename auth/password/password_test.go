@@ -0,0 +1,62 @@
+package password
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestVerifyRejectsInvalidArgon2Params(t *testing.T) {
+	salt := base64.RawStdEncoding.EncodeToString([]byte("0123456789abcdef"))
+	key := base64.RawStdEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef"))
+	short := base64.RawStdEncoding.EncodeToString([]byte("short"))
+
+	tests := []struct {
+		name    string
+		encoded string
+	}{
+		{"zero iterations", "$argon2id$v=19$m=65536,t=0,p=2$" + salt + "$" + key},
+		{"too many iterations", "$argon2id$v=19$m=65536,t=1000,p=2$" + salt + "$" + key},
+		{"zero parallelism", "$argon2id$v=19$m=65536,t=3,p=0$" + salt + "$" + key},
+		{"parallelism overflow", "$argon2id$v=19$m=65536,t=3,p=256$" + salt + "$" + key},
+		{"zero memory", "$argon2id$v=19$m=0,t=3,p=2$" + salt + "$" + key},
+		{"memory below 8 per lane", "$argon2id$v=19$m=8,t=3,p=2$" + salt + "$" + key},
+		{"too much memory", "$argon2id$v=19$m=4294967295,t=3,p=2$" + salt + "$" + key},
+		{"short salt", "$argon2id$v=19$m=65536,t=3,p=2$" + short + "$" + key},
+		{"empty key", "$argon2id$v=19$m=65536,t=3,p=2$" + salt + "$"},
+		{"short key", "$argon2id$v=19$m=65536,t=3,p=2$" + salt + "$" + short},
+		{"wrong version", "$argon2id$v=16$m=65536,t=3,p=2$" + salt + "$" + key},
+		{"missing field", "$argon2id$v=19$m=65536,t=3,p=2$" + salt},
+	}
+	h := NewDefaultHasher()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := h.Verify("Correct Horse Battery 9", tt.encoded); !errors.Is(err, ErrInvalidHash) {
+				t.Fatalf("Verify: got %v, want ErrInvalidHash", err)
+			}
+			if !h.NeedsRehash(tt.encoded) {
+				t.Fatal("NeedsRehash = false for an invalid hash")
+			}
+		})
+	}
+}
+
+func TestHashAndVerify(t *testing.T) {
+	h := NewDefaultHasher()
+	encoded, err := h.Hash("Correct Horse Battery 9")
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+	if !strings.HasPrefix(encoded, "$argon2id$") {
+		t.Fatalf("hash %q is not argon2id", encoded)
+	}
+
+	needsRehash, err := h.Verify("Correct Horse Battery 9", encoded)
+	if err != nil || needsRehash {
+		t.Fatalf("Verify: needsRehash=%v err=%v", needsRehash, err)
+	}
+	if _, err := h.Verify("Wrong Horse Battery 9", encoded); !errors.Is(err, ErrMismatch) {
+		t.Fatalf("wrong password: got %v, want ErrMismatch", err)
+	}
+}
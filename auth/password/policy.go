@@ -0,0 +1,91 @@
+package password
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/domain"
+)
+
+// Policy is a configurable password strength policy.
+type Policy struct {
+	MinLength     int
+	MaxLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	// Forbidden lists passwords that are rejected regardless of the other rules,
+	// compared case-insensitively.
+	Forbidden []string
+}
+
+// DefaultPolicy returns a policy requiring 10-128 characters with mixed case and a digit.
+func DefaultPolicy() Policy {
+	return Policy{
+		MinLength:    10,
+		MaxLength:    128,
+		RequireUpper: true,
+		RequireLower: true,
+		RequireDigit: true,
+		Forbidden: []string{
+			"password123", "password1234", "qwerty12345", "1234567890", "kilatpet123",
+		},
+	}
+}
+
+// Validate checks password against the policy. Failures are returned as a
+// validation DomainError whose detail lists every unmet rule.
+func (p Policy) Validate(password string) error {
+	var problems []string
+
+	length := utf8.RuneCountInString(password)
+	if p.MinLength > 0 && length < p.MinLength {
+		problems = append(problems, fmt.Sprintf("must be at least %d characters", p.MinLength))
+	}
+	if p.MaxLength > 0 && length > p.MaxLength {
+		problems = append(problems, fmt.Sprintf("must be at most %d characters", p.MaxLength))
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+	if p.RequireUpper && !upper {
+		problems = append(problems, "must contain an uppercase letter")
+	}
+	if p.RequireLower && !lower {
+		problems = append(problems, "must contain a lowercase letter")
+	}
+	if p.RequireDigit && !digit {
+		problems = append(problems, "must contain a digit")
+	}
+	if p.RequireSymbol && !symbol {
+		problems = append(problems, "must contain a symbol")
+	}
+
+	for _, forbidden := range p.Forbidden {
+		if strings.EqualFold(password, forbidden) {
+			problems = append(problems, "is too common")
+			break
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	err := domain.NewValidationError("password does not meet the strength policy")
+	err.Detail = "password " + strings.Join(problems, ", ")
+	return err
+}
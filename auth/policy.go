@@ -0,0 +1,162 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/domain"
+	"go.yaml.in/yaml/v3"
+)
+
+// Permission is a named capability such as "booking:cancel" or "payout:read".
+// A permission of "*" grants everything and "booking:*" grants every
+// permission on the booking resource.
+type Permission string
+
+// PermissionAll grants every permission.
+const PermissionAll Permission = "*"
+
+// Policy maps each UserRole to the permissions it holds.
+// It is safe for concurrent use and can be replaced at runtime.
+type Policy struct {
+	mu    sync.RWMutex
+	roles map[UserRole]map[Permission]struct{}
+}
+
+// PolicyDocument is the file representation of a policy.
+//
+//	roles:
+//	  owner: ["booking:create", "booking:cancel"]
+//	  runner: ["booking:read", "payout:read"]
+//	  admin: ["*"]
+type PolicyDocument struct {
+	Roles map[UserRole][]Permission `json:"roles" yaml:"roles"`
+}
+
+// NewPolicy creates a policy from a role → permissions map.
+func NewPolicy(roles map[UserRole][]Permission) *Policy {
+	p := &Policy{roles: make(map[UserRole]map[Permission]struct{})}
+	for role, perms := range roles {
+		p.Grant(role, perms...)
+	}
+	return p
+}
+
+// ParsePolicy parses a policy document in "json" or "yaml" format.
+func ParsePolicy(data []byte, format string) (*Policy, error) {
+	var doc PolicyDocument
+	var err error
+	switch strings.ToLower(format) {
+	case "json":
+		err = json.Unmarshal(data, &doc)
+	case "yaml", "yml":
+		err = yaml.Unmarshal(data, &doc)
+	default:
+		return nil, fmt.Errorf("unsupported policy format: %s", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse policy: %w", err)
+	}
+	return NewPolicy(doc.Roles), nil
+}
+
+// LoadPolicyFile reads a policy from a .json, .yaml or .yml file.
+func LoadPolicyFile(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy %s: %w", path, err)
+	}
+	return ParsePolicy(data, strings.TrimPrefix(filepath.Ext(path), "."))
+}
+
+// Grant adds permissions to a role.
+func (p *Policy) Grant(role UserRole, perms ...Permission) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	set, ok := p.roles[role]
+	if !ok {
+		set = make(map[Permission]struct{})
+		p.roles[role] = set
+	}
+	for _, perm := range perms {
+		set[perm] = struct{}{}
+	}
+}
+
+// Revoke removes permissions from a role.
+func (p *Policy) Revoke(role UserRole, perms ...Permission) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, perm := range perms {
+		delete(p.roles[role], perm)
+	}
+}
+
+// Replace atomically swaps the policy contents with those of other.
+func (p *Policy) Replace(other *Policy) {
+	other.mu.RLock()
+	roles := make(map[UserRole]map[Permission]struct{}, len(other.roles))
+	for role, set := range other.roles {
+		copied := make(map[Permission]struct{}, len(set))
+		for perm := range set {
+			copied[perm] = struct{}{}
+		}
+		roles[role] = copied
+	}
+	other.mu.RUnlock()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.roles = roles
+}
+
+// Allows returns true if the role holds the permission directly or via a wildcard.
+func (p *Policy) Allows(role UserRole, perm Permission) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	set, ok := p.roles[role]
+	if !ok {
+		return false
+	}
+	if _, ok := set[perm]; ok {
+		return true
+	}
+	if _, ok := set[PermissionAll]; ok {
+		return true
+	}
+	if resource, _, found := strings.Cut(string(perm), ":"); found {
+		if _, ok := set[Permission(resource+":*")]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Authorize returns a forbidden DomainError naming the first permission the role lacks.
+func (p *Policy) Authorize(role UserRole, perms ...Permission) error {
+	for _, perm := range perms {
+		if !p.Allows(role, perm) {
+			err := domain.NewForbiddenError(fmt.Sprintf("missing permission '%s'", perm))
+			err.Detail = fmt.Sprintf("role '%s' does not have permission '%s'", role, perm)
+			return err
+		}
+	}
+	return nil
+}
+
+// Permissions returns the sorted permissions granted to a role.
+func (p *Policy) Permissions(role UserRole) []Permission {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	perms := make([]Permission, 0, len(p.roles[role]))
+	for perm := range p.roles[role] {
+		perms = append(perms, perm)
+	}
+	sort.Slice(perms, func(i, j int) bool { return perms[i] < perms[j] })
+	return perms
+}
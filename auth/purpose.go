@@ -0,0 +1,118 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Purpose token errors.
+var (
+	ErrWrongPurpose         = errors.New("token was issued for a different purpose")
+	ErrTokenConsumed        = errors.New("token has already been used")
+	ErrNoConsumedTokenStore = errors.New("no consumed token store configured")
+	ErrUnknownPurpose       = errors.New("unknown token purpose")
+)
+
+// Purpose is what a single-use token may be used for.
+type Purpose string
+
+const (
+	PurposeEmailVerify   Purpose = "email_verify"
+	PurposePasswordReset Purpose = "password_reset"
+	PurposeMagicLogin    Purpose = "magic_login"
+	PurposeInvite        Purpose = "invite"
+)
+
+// defaultPurposeExpiry is the lifetime of each purpose unless overridden with WithPurposeExpiry.
+var defaultPurposeExpiry = map[Purpose]time.Duration{
+	PurposeEmailVerify:   24 * time.Hour,
+	PurposePasswordReset: 30 * time.Minute,
+	PurposeMagicLogin:    15 * time.Minute,
+	PurposeInvite:        7 * 24 * time.Hour,
+}
+
+// ConsumedTokenStore records single-use tokens that have been redeemed.
+type ConsumedTokenStore interface {
+	// Consume marks jti as used until expiresAt. It returns ErrTokenConsumed
+	// if the token was already consumed.
+	Consume(ctx context.Context, jti string, expiresAt time.Time) error
+}
+
+// WithPurposeExpiry sets the lifetime of tokens issued for a purpose.
+func WithPurposeExpiry(purpose Purpose, expiry time.Duration) Option {
+	return func(m *JWTManager) {
+		if m.purposeExpiry == nil {
+			m.purposeExpiry = make(map[Purpose]time.Duration)
+		}
+		m.purposeExpiry[purpose] = expiry
+	}
+}
+
+// WithConsumedTokenStore sets the store that enforces single use of purpose tokens.
+func WithConsumedTokenStore(store ConsumedTokenStore) Option {
+	return func(m *JWTManager) {
+		m.consumed = store
+	}
+}
+
+// GeneratePurposeToken creates a single-use token for the given purpose.
+// userID may be uuid.Nil for invites to people without an account yet.
+func (m *JWTManager) GeneratePurposeToken(purpose Purpose, userID uuid.UUID, email string) (string, error) {
+	expiry, ok := m.expiryFor(purpose)
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownPurpose, purpose)
+	}
+	claims := &Claims{
+		RegisteredClaims: m.registeredClaims(userID, expiry),
+		UserID:           userID,
+		Email:            email,
+		TokenType:        PurposeToken,
+		Purpose:          purpose,
+	}
+	return m.sign(claims)
+}
+
+// ValidatePurposeToken validates a purpose token without consuming it,
+// for example to render a password reset form.
+func (m *JWTManager) ValidatePurposeToken(ctx context.Context, tokenString string, purpose Purpose) (*Claims, error) {
+	claims, err := m.ValidateTokenContext(ctx, tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.TokenType != PurposeToken {
+		return nil, fmt.Errorf("%w: not a purpose token", ErrWrongTokenType)
+	}
+	if claims.Purpose != purpose {
+		return nil, fmt.Errorf("%w: got %q, want %q", ErrWrongPurpose, claims.Purpose, purpose)
+	}
+	return claims, nil
+}
+
+// ConsumePurposeToken validates a purpose token and marks it used so it
+// cannot be redeemed again, including during the validation leeway after exp.
+func (m *JWTManager) ConsumePurposeToken(ctx context.Context, tokenString string, purpose Purpose) (*Claims, error) {
+	if m.consumed == nil {
+		return nil, ErrNoConsumedTokenStore
+	}
+	claims, err := m.ValidatePurposeToken(ctx, tokenString, purpose)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.consumed.Consume(ctx, claims.ID, claims.ExpiresAt.Time.Add(m.leeway)); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// expiryFor returns the configured lifetime of a purpose.
+func (m *JWTManager) expiryFor(purpose Purpose) (time.Duration, bool) {
+	if expiry, ok := m.purposeExpiry[purpose]; ok {
+		return expiry, true
+	}
+	expiry, ok := defaultPurposeExpiry[purpose]
+	return expiry, ok
+}
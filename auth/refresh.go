@@ -0,0 +1,234 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// Refresh token errors.
+var (
+	ErrTokenFamilyNotFound = errors.New("refresh token family not found")
+	ErrTokenFamilyRevoked  = errors.New("refresh token family has been revoked")
+	ErrRefreshTokenReused  = errors.New("refresh token has already been used")
+)
+
+// Revocation reasons recorded on a token family.
+const (
+	RevokeReasonReuse  = "reuse_detected"
+	RevokeReasonLogout = "logout"
+	RevokeReasonAdmin  = "admin"
+)
+
+// TokenFamily tracks a chain of rotated refresh tokens that started from one login.
+// Only CurrentJTI may be exchanged; presenting any older token from the
+// family is treated as theft and revokes the whole family.
+type TokenFamily struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+	Email  string
+	Role   UserRole
+	// MFAAt, Scope and Tenant are the optional claims the family was issued
+	// with. They are carried over to every access token it rotates into.
+	MFAAt        *time.Time
+	Scope        string
+	Tenant       *TenantClaim
+	CurrentJTI   string
+	CreatedAt    time.Time
+	RotatedAt    time.Time
+	ExpiresAt    time.Time
+	RevokedAt    *time.Time
+	RevokeReason string
+}
+
+// IsRevoked returns true if the family has been revoked.
+func (f *TokenFamily) IsRevoked() bool {
+	return f.RevokedAt != nil
+}
+
+// accessClaims returns a token option restoring the family's optional claims.
+func (f *TokenFamily) accessClaims() TokenOption {
+	return func(c *Claims) {
+		if f.MFAAt != nil {
+			c.MFAAt = jwt.NewNumericDate(*f.MFAAt)
+		}
+		c.Scope = f.Scope
+		if f.Tenant != nil {
+			tenant := *f.Tenant
+			c.Tenant = &tenant
+		}
+	}
+}
+
+// RefreshTokenStore persists refresh token families.
+type RefreshTokenStore interface {
+	// CreateFamily stores a new token family.
+	CreateFamily(ctx context.Context, family *TokenFamily) error
+	// GetFamily returns the family or ErrTokenFamilyNotFound.
+	GetFamily(ctx context.Context, familyID uuid.UUID) (*TokenFamily, error)
+	// RotateFamily atomically replaces currentJTI with nextJTI. It returns
+	// ErrRefreshTokenReused if currentJTI is no longer current and
+	// ErrTokenFamilyRevoked if the family was revoked.
+	RotateFamily(ctx context.Context, familyID uuid.UUID, currentJTI, nextJTI string, expiresAt time.Time) error
+	// RevokeFamily revokes a single family.
+	RevokeFamily(ctx context.Context, familyID uuid.UUID, reason string) error
+	// RevokeUserFamilies revokes every family belonging to a user.
+	RevokeUserFamilies(ctx context.Context, userID uuid.UUID, reason string) error
+}
+
+// TokenPair is an access token and refresh token issued together.
+type TokenPair struct {
+	AccessToken      string    `json:"access_token"`
+	RefreshToken     string    `json:"refresh_token"`
+	AccessExpiresAt  time.Time `json:"access_expires_at"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+	FamilyID         uuid.UUID `json:"-"`
+}
+
+// RefreshTokenService issues token pairs and exchanges refresh tokens with
+// rotation and reuse detection.
+type RefreshTokenService struct {
+	jwtManager *JWTManager
+	store      RefreshTokenStore
+}
+
+// NewRefreshTokenService creates a new refresh token service.
+func NewRefreshTokenService(jwtManager *JWTManager, store RefreshTokenStore) *RefreshTokenService {
+	return &RefreshTokenService{jwtManager: jwtManager, store: store}
+}
+
+// Issue starts a new token family, typically on login. The MFA, scope and
+// tenant claims set by opts are kept for the life of the family.
+func (s *RefreshTokenService) Issue(ctx context.Context, userID uuid.UUID, email string, role UserRole, opts ...TokenOption) (*TokenPair, error) {
+	var optional Claims
+	for _, opt := range opts {
+		opt(&optional)
+	}
+
+	now := time.Now().UTC()
+	family := &TokenFamily{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Email:     email,
+		Role:      role,
+		Scope:     optional.Scope,
+		Tenant:    optional.Tenant,
+		CreatedAt: now,
+		RotatedAt: now,
+	}
+	if optional.MFAAt != nil {
+		mfaAt := optional.MFAAt.UTC()
+		family.MFAAt = &mfaAt
+	}
+
+	pair, refreshClaims, err := s.issuePair(family)
+	if err != nil {
+		return nil, err
+	}
+	family.CurrentJTI = refreshClaims.ID
+	family.ExpiresAt = refreshClaims.ExpiresAt.Time
+	if err := s.store.CreateFamily(ctx, family); err != nil {
+		return nil, fmt.Errorf("failed to create token family: %w", err)
+	}
+	return pair, nil
+}
+
+// Rotate exchanges a refresh token for a new access/refresh pair. If the
+// token was already exchanged, the whole family is revoked and
+// ErrRefreshTokenReused is returned.
+func (s *RefreshTokenService) Rotate(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	claims, err := s.jwtManager.ValidateRefreshTokenContext(ctx, refreshToken)
+	if err != nil {
+		return nil, err
+	}
+	familyID, err := uuid.Parse(claims.FamilyID)
+	if err != nil {
+		return nil, ErrTokenFamilyNotFound
+	}
+
+	family, err := s.store.GetFamily(ctx, familyID)
+	if err != nil {
+		return nil, err
+	}
+	if family.IsRevoked() {
+		return nil, ErrTokenFamilyRevoked
+	}
+	if family.UserID != claims.UserID {
+		return nil, ErrTokenFamilyNotFound
+	}
+
+	pair, next, err := s.issuePair(family)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.store.RotateFamily(ctx, familyID, claims.ID, next.ID, next.ExpiresAt.Time)
+	if errors.Is(err, ErrRefreshTokenReused) {
+		if revokeErr := s.store.RevokeFamily(ctx, familyID, RevokeReasonReuse); revokeErr != nil {
+			return nil, fmt.Errorf("failed to revoke reused token family: %w", revokeErr)
+		}
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	return pair, nil
+}
+
+// Revoke revokes the family that a refresh token belongs to, typically on logout.
+func (s *RefreshTokenService) Revoke(ctx context.Context, refreshToken string) error {
+	familyID, err := s.familyOf(ctx, refreshToken)
+	if err != nil {
+		return err
+	}
+	return s.store.RevokeFamily(ctx, familyID, RevokeReasonLogout)
+}
+
+// familyOf validates a refresh token and returns its family ID.
+func (s *RefreshTokenService) familyOf(ctx context.Context, refreshToken string) (uuid.UUID, error) {
+	claims, err := s.jwtManager.ValidateRefreshTokenContext(ctx, refreshToken)
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+	familyID, err := uuid.Parse(claims.FamilyID)
+	if err != nil {
+		return uuid.UUID{}, ErrTokenFamilyNotFound
+	}
+	return familyID, nil
+}
+
+// RevokeFamily revokes a token family by ID.
+func (s *RefreshTokenService) RevokeFamily(ctx context.Context, familyID uuid.UUID, reason string) error {
+	return s.store.RevokeFamily(ctx, familyID, reason)
+}
+
+// RevokeUser revokes every token family belonging to a user. It does not
+// know about sessions; use SessionManager.RevokeUser to sign them out too.
+func (s *RefreshTokenService) RevokeUser(ctx context.Context, userID uuid.UUID, reason string) error {
+	return s.store.RevokeUserFamilies(ctx, userID, reason)
+}
+
+// issuePair signs an access and refresh token bound to the family.
+func (s *RefreshTokenService) issuePair(family *TokenFamily) (*TokenPair, *Claims, error) {
+	familyID := family.ID.String()
+	accessToken, accessClaims, err := s.jwtManager.generateAccessToken(family.UserID, family.Email, family.Role, familyID, family.accessClaims())
+	if err != nil {
+		return nil, nil, err
+	}
+	refreshToken, refreshClaims, err := s.jwtManager.generateRefreshToken(family.UserID, familyID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:      accessToken,
+		RefreshToken:     refreshToken,
+		AccessExpiresAt:  accessClaims.ExpiresAt.Time,
+		RefreshExpiresAt: refreshClaims.ExpiresAt.Time,
+		FamilyID:         family.ID,
+	}, refreshClaims, nil
+}
@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MemoryRefreshTokenStore is an in-process RefreshTokenStore for tests and
+// single-instance deployments.
+type MemoryRefreshTokenStore struct {
+	mu       sync.Mutex
+	families map[uuid.UUID]*TokenFamily
+}
+
+// NewMemoryRefreshTokenStore creates an empty in-memory store.
+func NewMemoryRefreshTokenStore() *MemoryRefreshTokenStore {
+	return &MemoryRefreshTokenStore{families: make(map[uuid.UUID]*TokenFamily)}
+}
+
+// CreateFamily stores a new token family.
+func (s *MemoryRefreshTokenStore) CreateFamily(_ context.Context, family *TokenFamily) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f := *family
+	s.families[family.ID] = &f
+	return nil
+}
+
+// GetFamily returns a copy of the family.
+func (s *MemoryRefreshTokenStore) GetFamily(_ context.Context, familyID uuid.UUID) (*TokenFamily, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, ok := s.families[familyID]
+	if !ok {
+		return nil, ErrTokenFamilyNotFound
+	}
+	out := *f
+	return &out, nil
+}
+
+// RotateFamily atomically replaces currentJTI with nextJTI.
+func (s *MemoryRefreshTokenStore) RotateFamily(_ context.Context, familyID uuid.UUID, currentJTI, nextJTI string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, ok := s.families[familyID]
+	if !ok {
+		return ErrTokenFamilyNotFound
+	}
+	if f.IsRevoked() {
+		return ErrTokenFamilyRevoked
+	}
+	if f.CurrentJTI != currentJTI {
+		return ErrRefreshTokenReused
+	}
+	f.CurrentJTI = nextJTI
+	f.RotatedAt = time.Now().UTC()
+	f.ExpiresAt = expiresAt
+	return nil
+}
+
+// RevokeFamily revokes a single family.
+func (s *MemoryRefreshTokenStore) RevokeFamily(_ context.Context, familyID uuid.UUID, reason string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, ok := s.families[familyID]
+	if ok && !f.IsRevoked() {
+		now := time.Now().UTC()
+		f.RevokedAt = &now
+		f.RevokeReason = reason
+	}
+	return nil
+}
+
+// RevokeUserFamilies revokes every family belonging to a user.
+func (s *MemoryRefreshTokenStore) RevokeUserFamilies(_ context.Context, userID uuid.UUID, reason string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now().UTC()
+	for _, f := range s.families {
+		if f.UserID == userID && !f.IsRevoked() {
+			f.RevokedAt = &now
+			f.RevokeReason = reason
+		}
+	}
+	return nil
+}
+
+// DeleteExpired removes families whose latest refresh token has expired.
+func (s *MemoryRefreshTokenStore) DeleteExpired(_ context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	var n int64
+	for id, f := range s.families {
+		if now.After(f.ExpiresAt) {
+			delete(s.families, id)
+			n++
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,169 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// refreshTokenFamilyModel is the GORM model for the refresh_token_families table.
+type refreshTokenFamilyModel struct {
+	ID           uuid.UUID `gorm:"type:uuid;primaryKey"`
+	UserID       uuid.UUID `gorm:"type:uuid;not null;index"`
+	Email        string    `gorm:"not null;default:''"`
+	Role         string    `gorm:"not null;default:''"`
+	MFAAt        *time.Time
+	Scope        string     `gorm:"not null;default:''"`
+	TenantID     *uuid.UUID `gorm:"type:uuid"`
+	TenantRole   string     `gorm:"not null;default:''"`
+	CurrentJTI   string     `gorm:"column:current_jti;not null"`
+	CreatedAt    time.Time  `gorm:"not null"`
+	RotatedAt    time.Time  `gorm:"not null"`
+	ExpiresAt    time.Time  `gorm:"not null;index"`
+	RevokedAt    *time.Time
+	RevokeReason string `gorm:"not null;default:''"`
+}
+
+// TableName sets the table name for GORM.
+func (refreshTokenFamilyModel) TableName() string { return "refresh_token_families" }
+
+func (m *refreshTokenFamilyModel) toFamily() *TokenFamily {
+	family := &TokenFamily{
+		ID:           m.ID,
+		UserID:       m.UserID,
+		Email:        m.Email,
+		Role:         UserRole(m.Role),
+		MFAAt:        m.MFAAt,
+		Scope:        m.Scope,
+		CurrentJTI:   m.CurrentJTI,
+		CreatedAt:    m.CreatedAt,
+		RotatedAt:    m.RotatedAt,
+		ExpiresAt:    m.ExpiresAt,
+		RevokedAt:    m.RevokedAt,
+		RevokeReason: m.RevokeReason,
+	}
+	if m.TenantID != nil {
+		family.Tenant = &TenantClaim{ID: *m.TenantID, Role: TenantRole(m.TenantRole)}
+	}
+	return family
+}
+
+// PostgresRefreshTokenStore is a GORM-backed RefreshTokenStore.
+type PostgresRefreshTokenStore struct {
+	db *gorm.DB
+}
+
+// NewPostgresRefreshTokenStore creates a Postgres refresh token store.
+func NewPostgresRefreshTokenStore(db *gorm.DB) *PostgresRefreshTokenStore {
+	return &PostgresRefreshTokenStore{db: db}
+}
+
+// AutoMigrate creates or updates the refresh_token_families table.
+func (s *PostgresRefreshTokenStore) AutoMigrate() error {
+	return s.db.AutoMigrate(&refreshTokenFamilyModel{})
+}
+
+// CreateFamily stores a new token family.
+func (s *PostgresRefreshTokenStore) CreateFamily(ctx context.Context, family *TokenFamily) error {
+	model := refreshTokenFamilyModel{
+		ID:         family.ID,
+		UserID:     family.UserID,
+		Email:      family.Email,
+		Role:       string(family.Role),
+		MFAAt:      family.MFAAt,
+		Scope:      family.Scope,
+		CurrentJTI: family.CurrentJTI,
+		CreatedAt:  family.CreatedAt,
+		RotatedAt:  family.RotatedAt,
+		ExpiresAt:  family.ExpiresAt,
+	}
+	if family.Tenant != nil {
+		model.TenantID = &family.Tenant.ID
+		model.TenantRole = string(family.Tenant.Role)
+	}
+	if err := s.db.WithContext(ctx).Create(&model).Error; err != nil {
+		return fmt.Errorf("failed to create token family: %w", err)
+	}
+	return nil
+}
+
+// GetFamily returns the family or ErrTokenFamilyNotFound.
+func (s *PostgresRefreshTokenStore) GetFamily(ctx context.Context, familyID uuid.UUID) (*TokenFamily, error) {
+	var model refreshTokenFamilyModel
+	err := s.db.WithContext(ctx).Where("id = ?", familyID).First(&model).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrTokenFamilyNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load token family: %w", err)
+	}
+	return model.toFamily(), nil
+}
+
+// RotateFamily atomically replaces currentJTI with nextJTI using a conditional update.
+func (s *PostgresRefreshTokenStore) RotateFamily(ctx context.Context, familyID uuid.UUID, currentJTI, nextJTI string, expiresAt time.Time) error {
+	result := s.db.WithContext(ctx).Model(&refreshTokenFamilyModel{}).
+		Where("id = ? AND current_jti = ? AND revoked_at IS NULL", familyID, currentJTI).
+		Updates(map[string]interface{}{
+			"current_jti": nextJTI,
+			"rotated_at":  time.Now().UTC(),
+			"expires_at":  expiresAt,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to rotate token family: %w", result.Error)
+	}
+	if result.RowsAffected == 1 {
+		return nil
+	}
+
+	// Nothing updated: work out why.
+	family, err := s.GetFamily(ctx, familyID)
+	if err != nil {
+		return err
+	}
+	if family.IsRevoked() {
+		return ErrTokenFamilyRevoked
+	}
+	return ErrRefreshTokenReused
+}
+
+// RevokeFamily revokes a single family.
+func (s *PostgresRefreshTokenStore) RevokeFamily(ctx context.Context, familyID uuid.UUID, reason string) error {
+	err := s.db.WithContext(ctx).Model(&refreshTokenFamilyModel{}).
+		Where("id = ? AND revoked_at IS NULL", familyID).
+		Updates(map[string]interface{}{
+			"revoked_at":    time.Now().UTC(),
+			"revoke_reason": reason,
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to revoke token family: %w", err)
+	}
+	return nil
+}
+
+// RevokeUserFamilies revokes every family belonging to a user.
+func (s *PostgresRefreshTokenStore) RevokeUserFamilies(ctx context.Context, userID uuid.UUID, reason string) error {
+	err := s.db.WithContext(ctx).Model(&refreshTokenFamilyModel{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Updates(map[string]interface{}{
+			"revoked_at":    time.Now().UTC(),
+			"revoke_reason": reason,
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to revoke user token families: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpired removes families whose latest refresh token has expired.
+func (s *PostgresRefreshTokenStore) DeleteExpired(ctx context.Context) (int64, error) {
+	result := s.db.WithContext(ctx).Where("expires_at < ?", time.Now().UTC()).Delete(&refreshTokenFamilyModel{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete expired token families: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,29 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Revocation errors.
+var (
+	ErrTokenRevoked      = errors.New("token has been revoked")
+	ErrNoRevocationStore = errors.New("no revocation store configured")
+)
+
+// RevocationStore is a denylist of revoked tokens, keyed by jti, plus
+// per-user "revoke everything issued before T" markers. Entries carry an
+// expiry after which the store may drop them because the tokens they cover
+// can no longer validate anyway.
+type RevocationStore interface {
+	// RevokeToken revokes a single token until expiresAt.
+	RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error
+	// RevokeUserTokensBefore revokes every token for userID whose iat is
+	// strictly before the given time. The marker is kept until expiresAt.
+	RevokeUserTokensBefore(ctx context.Context, userID uuid.UUID, before, expiresAt time.Time) error
+	// IsRevoked reports whether the token described by claims is revoked.
+	IsRevoked(ctx context.Context, claims *Claims) (bool, error)
+}
@@ -0,0 +1,111 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type userRevocation struct {
+	before    time.Time
+	expiresAt time.Time
+}
+
+// revocationSweepInterval limits how often writes prune expired entries.
+const revocationSweepInterval = time.Minute
+
+// MemoryRevocationStore is an in-process RevocationStore. Expired entries
+// are pruned as new revocations are written.
+type MemoryRevocationStore struct {
+	mu        sync.RWMutex
+	tokens    map[string]time.Time
+	users     map[uuid.UUID]userRevocation
+	lastSweep time.Time
+}
+
+// NewMemoryRevocationStore creates an empty in-memory revocation store.
+func NewMemoryRevocationStore() *MemoryRevocationStore {
+	return &MemoryRevocationStore{
+		tokens: make(map[string]time.Time),
+		users:  make(map[uuid.UUID]userRevocation),
+	}
+}
+
+// RevokeToken revokes a single token until expiresAt.
+func (s *MemoryRevocationStore) RevokeToken(_ context.Context, jti string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.maybeSweep(time.Now())
+	s.tokens[jti] = expiresAt
+	return nil
+}
+
+// RevokeUserTokensBefore revokes every token for userID issued before the given time.
+func (s *MemoryRevocationStore) RevokeUserTokensBefore(_ context.Context, userID uuid.UUID, before, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.maybeSweep(time.Now())
+	existing, ok := s.users[userID]
+	if ok && existing.before.After(before) {
+		before = existing.before
+	}
+	if ok && existing.expiresAt.After(expiresAt) {
+		expiresAt = existing.expiresAt
+	}
+	s.users[userID] = userRevocation{before: before, expiresAt: expiresAt}
+	return nil
+}
+
+// IsRevoked reports whether the token described by claims is revoked.
+func (s *MemoryRevocationStore) IsRevoked(_ context.Context, claims *Claims) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	now := time.Now()
+
+	if expiresAt, ok := s.tokens[claims.ID]; ok && now.Before(expiresAt) {
+		return true, nil
+	}
+	if ur, ok := s.users[claims.UserID]; ok && now.Before(ur.expiresAt) {
+		if claims.IssuedAt == nil || claims.IssuedAt.Before(ur.before) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// DeleteExpired removes entries whose tokens can no longer validate.
+func (s *MemoryRevocationStore) DeleteExpired(_ context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sweep(time.Now()), nil
+}
+
+// maybeSweep prunes expired entries at most once per revocationSweepInterval.
+// The caller must hold s.mu.
+func (s *MemoryRevocationStore) maybeSweep(now time.Time) {
+	if now.Sub(s.lastSweep) >= revocationSweepInterval {
+		s.sweep(now)
+	}
+}
+
+// sweep removes expired entries and returns how many it removed. The caller
+// must hold s.mu.
+func (s *MemoryRevocationStore) sweep(now time.Time) int64 {
+	var n int64
+	for jti, expiresAt := range s.tokens {
+		if !now.Before(expiresAt) {
+			delete(s.tokens, jti)
+			n++
+		}
+	}
+	for userID, ur := range s.users {
+		if !now.Before(ur.expiresAt) {
+			delete(s.users, userID)
+			n++
+		}
+	}
+	s.lastSweep = now
+	return n
+}
@@ -0,0 +1,106 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// revokedTokenModel is the GORM model for the revoked_tokens table.
+type revokedTokenModel struct {
+	JTI       string    `gorm:"column:jti;primaryKey"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+}
+
+// TableName sets the table name for GORM.
+func (revokedTokenModel) TableName() string { return "revoked_tokens" }
+
+// revokedUserTokensModel is the GORM model for the revoked_user_tokens table.
+type revokedUserTokensModel struct {
+	UserID        uuid.UUID `gorm:"type:uuid;primaryKey"`
+	RevokedBefore time.Time `gorm:"not null"`
+	ExpiresAt     time.Time `gorm:"not null;index"`
+}
+
+// TableName sets the table name for GORM.
+func (revokedUserTokensModel) TableName() string { return "revoked_user_tokens" }
+
+// PostgresRevocationStore is a GORM-backed RevocationStore.
+type PostgresRevocationStore struct {
+	db *gorm.DB
+}
+
+// NewPostgresRevocationStore creates a Postgres revocation store.
+func NewPostgresRevocationStore(db *gorm.DB) *PostgresRevocationStore {
+	return &PostgresRevocationStore{db: db}
+}
+
+// AutoMigrate creates or updates the revocation tables.
+func (s *PostgresRevocationStore) AutoMigrate() error {
+	return s.db.AutoMigrate(&revokedTokenModel{}, &revokedUserTokensModel{})
+}
+
+// RevokeToken revokes a single token until expiresAt.
+func (s *PostgresRevocationStore) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	err := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "jti"}},
+		DoUpdates: clause.AssignmentColumns([]string{"expires_at"}),
+	}).Create(&revokedTokenModel{JTI: jti, ExpiresAt: expiresAt}).Error
+	if err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+	return nil
+}
+
+// RevokeUserTokensBefore revokes every token for userID issued before the given time.
+func (s *PostgresRevocationStore) RevokeUserTokensBefore(ctx context.Context, userID uuid.UUID, before, expiresAt time.Time) error {
+	err := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.Set{
+			{Column: clause.Column{Name: "revoked_before"}, Value: gorm.Expr("GREATEST(revoked_user_tokens.revoked_before, EXCLUDED.revoked_before)")},
+			{Column: clause.Column{Name: "expires_at"}, Value: gorm.Expr("GREATEST(revoked_user_tokens.expires_at, EXCLUDED.expires_at)")},
+		},
+	}).Create(&revokedUserTokensModel{UserID: userID, RevokedBefore: before, ExpiresAt: expiresAt}).Error
+	if err != nil {
+		return fmt.Errorf("failed to revoke user tokens: %w", err)
+	}
+	return nil
+}
+
+// IsRevoked reports whether the token described by claims is revoked.
+func (s *PostgresRevocationStore) IsRevoked(ctx context.Context, claims *Claims) (bool, error) {
+	var issuedAt time.Time
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Time
+	}
+
+	var revoked bool
+	err := s.db.WithContext(ctx).Raw(`
+		SELECT EXISTS (
+			SELECT 1 FROM revoked_tokens WHERE jti = ? AND expires_at > NOW()
+		) OR EXISTS (
+			SELECT 1 FROM revoked_user_tokens WHERE user_id = ? AND revoked_before > ? AND expires_at > NOW()
+		)`, claims.ID, claims.UserID, issuedAt).Scan(&revoked).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to check token revocation: %w", err)
+	}
+	return revoked, nil
+}
+
+// DeleteExpired removes entries whose tokens can no longer validate.
+func (s *PostgresRevocationStore) DeleteExpired(ctx context.Context) (int64, error) {
+	now := time.Now().UTC()
+	tokens := s.db.WithContext(ctx).Where("expires_at <= ?", now).Delete(&revokedTokenModel{})
+	if tokens.Error != nil {
+		return 0, fmt.Errorf("failed to delete expired revoked tokens: %w", tokens.Error)
+	}
+	users := s.db.WithContext(ctx).Where("expires_at <= ?", now).Delete(&revokedUserTokensModel{})
+	if users.Error != nil {
+		return tokens.RowsAffected, fmt.Errorf("failed to delete expired user revocations: %w", users.Error)
+	}
+	return tokens.RowsAffected + users.RowsAffected, nil
+}
@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// newLeewayManager returns a manager whose tokens are already past exp but
+// still inside the validation leeway.
+func newLeewayManager(opts ...Option) *JWTManager {
+	opts = append([]Option{WithLeeway(time.Minute)}, opts...)
+	return NewJWTManager("test-secret", -30*time.Second, time.Hour, opts...)
+}
+
+func TestRevokeTokenCoversLeeway(t *testing.T) {
+	ctx := context.Background()
+	m := newLeewayManager(WithRevocationStore(NewMemoryRevocationStore()))
+
+	token, err := m.GenerateAccessToken(uuid.New(), "owner@example.com", RoleOwner)
+	if err != nil {
+		t.Fatalf("GenerateAccessToken: %v", err)
+	}
+	claims, err := m.ValidateAccessTokenContext(ctx, token)
+	if err != nil {
+		t.Fatalf("token past exp was rejected inside the leeway: %v", err)
+	}
+
+	if err := m.RevokeToken(ctx, claims); err != nil {
+		t.Fatalf("RevokeToken: %v", err)
+	}
+	if _, err := m.ValidateAccessTokenContext(ctx, token); !errors.Is(err, ErrTokenRevoked) {
+		t.Fatalf("revoked token inside the leeway: got %v, want ErrTokenRevoked", err)
+	}
+}
+
+func TestConsumePurposeTokenCoversLeeway(t *testing.T) {
+	ctx := context.Background()
+	m := newLeewayManager(
+		WithPurposeExpiry(PurposePasswordReset, -30*time.Second),
+		WithConsumedTokenStore(NewMemoryConsumedTokenStore()),
+	)
+
+	token, err := m.GeneratePurposeToken(PurposePasswordReset, uuid.New(), "owner@example.com")
+	if err != nil {
+		t.Fatalf("GeneratePurposeToken: %v", err)
+	}
+	if _, err := m.ConsumePurposeToken(ctx, token, PurposePasswordReset); err != nil {
+		t.Fatalf("ConsumePurposeToken: %v", err)
+	}
+	if _, err := m.ConsumePurposeToken(ctx, token, PurposePasswordReset); !errors.Is(err, ErrTokenConsumed) {
+		t.Fatalf("second consume inside the leeway: got %v, want ErrTokenConsumed", err)
+	}
+}
+
+func TestRevokeUserTokensSparesLaterLogin(t *testing.T) {
+	ctx := context.Background()
+	m := NewJWTManager("test-secret", time.Minute, time.Hour, WithRevocationStore(NewMemoryRevocationStore()))
+	userID := uuid.New()
+
+	if err := m.RevokeUserTokens(ctx, userID, time.Now()); err != nil {
+		t.Fatalf("RevokeUserTokens: %v", err)
+	}
+	token, err := m.GenerateAccessToken(userID, "owner@example.com", RoleOwner)
+	if err != nil {
+		t.Fatalf("GenerateAccessToken: %v", err)
+	}
+	if _, err := m.ValidateAccessTokenContext(ctx, token); err != nil {
+		t.Fatalf("token issued after the revocation: %v", err)
+	}
+
+	if err := m.RevokeUserTokens(ctx, userID, time.Now().Add(time.Second)); err != nil {
+		t.Fatalf("RevokeUserTokens: %v", err)
+	}
+	if _, err := m.ValidateAccessTokenContext(ctx, token); !errors.Is(err, ErrTokenRevoked) {
+		t.Fatalf("token issued before the revocation: got %v, want ErrTokenRevoked", err)
+	}
+}
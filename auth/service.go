@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Service token errors.
+var (
+	ErrInvalidClientCredentials = errors.New("invalid client credentials")
+)
+
+// ServiceClient is a registered service principal for the client-credentials flow.
+type ServiceClient struct {
+	// Name is the client ID and the service_name claim on issued tokens.
+	Name string
+	// Secret is the shared client secret. Only its hash is kept in memory.
+	Secret string
+	// Audiences are the receivers the client may request tokens for.
+	Audiences []string
+}
+
+type registeredClient struct {
+	secretHash [sha256.Size]byte
+	audiences  map[string]struct{}
+}
+
+// ServiceRegistry exchanges client credentials for service tokens.
+type ServiceRegistry struct {
+	jwtManager *JWTManager
+	mu         sync.RWMutex
+	clients    map[string]registeredClient
+}
+
+// NewServiceRegistry creates a registry that issues tokens with jwtManager.
+func NewServiceRegistry(jwtManager *JWTManager, clients ...ServiceClient) *ServiceRegistry {
+	r := &ServiceRegistry{
+		jwtManager: jwtManager,
+		clients:    make(map[string]registeredClient),
+	}
+	for _, client := range clients {
+		r.Register(client)
+	}
+	return r
+}
+
+// Register adds or replaces a service client.
+func (r *ServiceRegistry) Register(client ServiceClient) {
+	audiences := make(map[string]struct{}, len(client.Audiences))
+	for _, aud := range client.Audiences {
+		audiences[aud] = struct{}{}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.clients[client.Name] = registeredClient{
+		secretHash: sha256.Sum256([]byte(client.Secret)),
+		audiences:  audiences,
+	}
+}
+
+// IssueToken verifies the client credentials and issues a service token for
+// the requested audiences, all of which must be allowed for the client.
+func (r *ServiceRegistry) IssueToken(clientName, clientSecret string, audiences ...string) (string, error) {
+	r.mu.RLock()
+	client, ok := r.clients[clientName]
+	r.mu.RUnlock()
+
+	hash := sha256.Sum256([]byte(clientSecret))
+	if !ok || subtle.ConstantTimeCompare(hash[:], client.secretHash[:]) != 1 {
+		return "", ErrInvalidClientCredentials
+	}
+	for _, aud := range audiences {
+		if _, allowed := client.audiences[aud]; !allowed {
+			return "", fmt.Errorf("%w: %s", ErrInvalidAudience, aud)
+		}
+	}
+	return r.jwtManager.GenerateServiceToken(clientName, audiences...)
+}
@@ -0,0 +1,208 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Session errors.
+var (
+	ErrSessionNotFound = errors.New("session not found")
+	ErrSessionRevoked  = errors.New("session has been revoked")
+	ErrSessionExpired  = errors.New("session has expired")
+)
+
+// DeviceInfo describes the client a session was started from.
+type DeviceInfo struct {
+	UserAgent string `json:"user_agent"`
+	IP        string `json:"ip"`
+	Platform  string `json:"platform"`
+}
+
+// Session is a signed-in device. Its ID is the refresh token family ID, so
+// every access token issued to the device carries it in the family_id claim.
+// ExpiresAt follows the family's refresh token expiry: it moves forward on
+// every refresh, so a device that stops refreshing drops out of the list.
+type Session struct {
+	ID         uuid.UUID  `json:"id"`
+	UserID     uuid.UUID  `json:"user_id"`
+	Device     DeviceInfo `json:"device"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// IsRevoked returns true if the session has been revoked.
+func (s *Session) IsRevoked() bool {
+	return s.RevokedAt != nil
+}
+
+// IsExpired returns true if the session's refresh token has expired.
+func (s *Session) IsExpired(now time.Time) bool {
+	return !now.Before(s.ExpiresAt)
+}
+
+// SessionStore persists sessions.
+type SessionStore interface {
+	// Create stores a new session.
+	Create(ctx context.Context, session *Session) error
+	// Get returns the session or ErrSessionNotFound.
+	Get(ctx context.Context, id uuid.UUID) (*Session, error)
+	// ListByUser returns the user's sessions that are neither revoked nor expired.
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*Session, error)
+	// Touch records activity on a session and moves its expiry forward to
+	// expiresAt. An expiresAt at or before the current expiry, such as the
+	// zero time, leaves the expiry unchanged.
+	Touch(ctx context.Context, id uuid.UUID, device DeviceInfo, at, expiresAt time.Time) error
+	// Revoke revokes a single session.
+	Revoke(ctx context.Context, id uuid.UUID) error
+	// RevokeByUser revokes every session belonging to a user.
+	RevokeByUser(ctx context.Context, userID uuid.UUID) error
+}
+
+// sessionTouchInterval limits how often last-seen is written for a session.
+const sessionTouchInterval = time.Minute
+
+// SessionManager links refresh token families to devices so users can see
+// and sign out their sessions.
+type SessionManager struct {
+	refresh *RefreshTokenService
+	store   SessionStore
+}
+
+// NewSessionManager creates a session manager.
+func NewSessionManager(refresh *RefreshTokenService, store SessionStore) *SessionManager {
+	return &SessionManager{refresh: refresh, store: store}
+}
+
+// Login issues a token pair and records the device it was issued to. The
+// MFA, scope and tenant claims set by opts are kept across refreshes.
+func (m *SessionManager) Login(ctx context.Context, userID uuid.UUID, email string, role UserRole, device DeviceInfo, opts ...TokenOption) (*TokenPair, error) {
+	pair, err := m.refresh.Issue(ctx, userID, email, role, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	session := &Session{
+		ID:         pair.FamilyID,
+		UserID:     userID,
+		Device:     device,
+		CreatedAt:  now,
+		LastSeenAt: now,
+		ExpiresAt:  pair.RefreshExpiresAt,
+	}
+	if err := m.store.Create(ctx, session); err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+	return pair, nil
+}
+
+// Refresh rotates a refresh token and records the device activity. If the
+// token was reused or its family is already revoked, the session is revoked
+// too so its outstanding access tokens stop working.
+func (m *SessionManager) Refresh(ctx context.Context, refreshToken string, device DeviceInfo) (*TokenPair, error) {
+	pair, err := m.refresh.Rotate(ctx, refreshToken)
+	if errors.Is(err, ErrRefreshTokenReused) || errors.Is(err, ErrTokenFamilyRevoked) {
+		if familyID, famErr := m.refresh.familyOf(ctx, refreshToken); famErr == nil {
+			if revokeErr := m.store.Revoke(ctx, familyID); revokeErr != nil && !errors.Is(revokeErr, ErrSessionNotFound) {
+				return nil, fmt.Errorf("failed to revoke session: %w", revokeErr)
+			}
+		}
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := m.store.Touch(ctx, pair.FamilyID, device, time.Now().UTC(), pair.RefreshExpiresAt); err != nil && !errors.Is(err, ErrSessionNotFound) {
+		return nil, fmt.Errorf("failed to update session: %w", err)
+	}
+	return pair, nil
+}
+
+// List returns the user's active sessions.
+func (m *SessionManager) List(ctx context.Context, userID uuid.UUID) ([]*Session, error) {
+	return m.store.ListByUser(ctx, userID)
+}
+
+// Revoke signs out one of the user's sessions.
+func (m *SessionManager) Revoke(ctx context.Context, userID, sessionID uuid.UUID) error {
+	session, err := m.store.Get(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if session.UserID != userID {
+		return ErrSessionNotFound
+	}
+	return m.revoke(ctx, sessionID)
+}
+
+// RevokeOthers signs out every session of the user except currentID and
+// returns how many were revoked.
+func (m *SessionManager) RevokeOthers(ctx context.Context, userID, currentID uuid.UUID) (int, error) {
+	sessions, err := m.store.ListByUser(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, s := range sessions {
+		if s.ID == currentID {
+			continue
+		}
+		if err := m.revoke(ctx, s.ID); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// RevokeUser signs out every session of the user and revokes their refresh
+// token families, for example after a password change.
+func (m *SessionManager) RevokeUser(ctx context.Context, userID uuid.UUID, reason string) error {
+	if err := m.store.RevokeByUser(ctx, userID); err != nil {
+		return err
+	}
+	return m.refresh.RevokeUser(ctx, userID, reason)
+}
+
+// Validate checks that a session is active and belongs to userID, and
+// records the activity at most once a minute.
+func (m *SessionManager) Validate(ctx context.Context, sessionID, userID uuid.UUID, device DeviceInfo) (*Session, error) {
+	session, err := m.store.Get(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.UserID != userID {
+		return nil, ErrSessionNotFound
+	}
+	if session.IsRevoked() {
+		return nil, ErrSessionRevoked
+	}
+	now := time.Now().UTC()
+	if session.IsExpired(now) {
+		return nil, ErrSessionExpired
+	}
+
+	if now.Sub(session.LastSeenAt) >= sessionTouchInterval {
+		if err := m.store.Touch(ctx, sessionID, device, now, time.Time{}); err != nil {
+			return nil, fmt.Errorf("failed to update session: %w", err)
+		}
+		session.Device = device
+		session.LastSeenAt = now
+	}
+	return session, nil
+}
+
+// revoke revokes the session and its refresh token family.
+func (m *SessionManager) revoke(ctx context.Context, sessionID uuid.UUID) error {
+	if err := m.store.Revoke(ctx, sessionID); err != nil {
+		return err
+	}
+	return m.refresh.RevokeFamily(ctx, sessionID, RevokeReasonLogout)
+}
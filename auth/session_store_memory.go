@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MemorySessionStore is an in-process SessionStore.
+type MemorySessionStore struct {
+	mu       sync.RWMutex
+	sessions map[uuid.UUID]*Session
+}
+
+// NewMemorySessionStore creates an empty in-memory session store.
+func NewMemorySessionStore() *MemorySessionStore {
+	return &MemorySessionStore{sessions: make(map[uuid.UUID]*Session)}
+}
+
+// Create stores a new session.
+func (s *MemorySessionStore) Create(_ context.Context, session *Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sess := *session
+	s.sessions[session.ID] = &sess
+	return nil
+}
+
+// Get returns the session or ErrSessionNotFound.
+func (s *MemorySessionStore) Get(_ context.Context, id uuid.UUID) (*Session, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	sess, ok := s.sessions[id]
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+	out := *sess
+	return &out, nil
+}
+
+// ListByUser returns the user's sessions that are neither revoked nor
+// expired, most recently seen first.
+func (s *MemorySessionStore) ListByUser(_ context.Context, userID uuid.UUID) ([]*Session, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	now := time.Now()
+	sessions := make([]*Session, 0)
+	for _, sess := range s.sessions {
+		if sess.UserID == userID && !sess.IsRevoked() && !sess.IsExpired(now) {
+			out := *sess
+			sessions = append(sessions, &out)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt) })
+	return sessions, nil
+}
+
+// Touch records activity on a session and moves its expiry forward to expiresAt.
+func (s *MemorySessionStore) Touch(_ context.Context, id uuid.UUID, device DeviceInfo, at, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sess, ok := s.sessions[id]
+	if !ok {
+		return ErrSessionNotFound
+	}
+	sess.Device = device
+	sess.LastSeenAt = at
+	if expiresAt.After(sess.ExpiresAt) {
+		sess.ExpiresAt = expiresAt
+	}
+	return nil
+}
+
+// Revoke revokes a single session.
+func (s *MemorySessionStore) Revoke(_ context.Context, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sess, ok := s.sessions[id]
+	if !ok {
+		return ErrSessionNotFound
+	}
+	if sess.RevokedAt == nil {
+		now := time.Now().UTC()
+		sess.RevokedAt = &now
+	}
+	return nil
+}
+
+// RevokeByUser revokes every session belonging to a user.
+func (s *MemorySessionStore) RevokeByUser(_ context.Context, userID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now().UTC()
+	for _, sess := range s.sessions {
+		if sess.UserID == userID && sess.RevokedAt == nil {
+			revokedAt := now
+			sess.RevokedAt = &revokedAt
+		}
+	}
+	return nil
+}
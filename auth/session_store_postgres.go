@@ -0,0 +1,161 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// sessionModel is the GORM model for the user_sessions table.
+type sessionModel struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey"`
+	UserID     uuid.UUID `gorm:"type:uuid;not null;index"`
+	UserAgent  string    `gorm:"not null;default:''"`
+	IP         string    `gorm:"column:ip;not null;default:''"`
+	Platform   string    `gorm:"not null;default:''"`
+	CreatedAt  time.Time `gorm:"not null"`
+	LastSeenAt time.Time `gorm:"not null"`
+	ExpiresAt  time.Time `gorm:"not null;index"`
+	RevokedAt  *time.Time
+}
+
+// TableName sets the table name for GORM.
+func (sessionModel) TableName() string { return "user_sessions" }
+
+func (m *sessionModel) toSession() *Session {
+	return &Session{
+		ID:     m.ID,
+		UserID: m.UserID,
+		Device: DeviceInfo{
+			UserAgent: m.UserAgent,
+			IP:        m.IP,
+			Platform:  m.Platform,
+		},
+		CreatedAt:  m.CreatedAt,
+		LastSeenAt: m.LastSeenAt,
+		ExpiresAt:  m.ExpiresAt,
+		RevokedAt:  m.RevokedAt,
+	}
+}
+
+// PostgresSessionStore is a GORM-backed SessionStore.
+type PostgresSessionStore struct {
+	db *gorm.DB
+}
+
+// NewPostgresSessionStore creates a Postgres session store.
+func NewPostgresSessionStore(db *gorm.DB) *PostgresSessionStore {
+	return &PostgresSessionStore{db: db}
+}
+
+// AutoMigrate creates or updates the user_sessions table.
+func (s *PostgresSessionStore) AutoMigrate() error {
+	return s.db.AutoMigrate(&sessionModel{})
+}
+
+// Create stores a new session.
+func (s *PostgresSessionStore) Create(ctx context.Context, session *Session) error {
+	model := sessionModel{
+		ID:         session.ID,
+		UserID:     session.UserID,
+		UserAgent:  session.Device.UserAgent,
+		IP:         session.Device.IP,
+		Platform:   session.Device.Platform,
+		CreatedAt:  session.CreatedAt,
+		LastSeenAt: session.LastSeenAt,
+		ExpiresAt:  session.ExpiresAt,
+	}
+	if err := s.db.WithContext(ctx).Create(&model).Error; err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+	return nil
+}
+
+// Get returns the session or ErrSessionNotFound.
+func (s *PostgresSessionStore) Get(ctx context.Context, id uuid.UUID) (*Session, error) {
+	var model sessionModel
+	err := s.db.WithContext(ctx).Where("id = ?", id).First(&model).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	return model.toSession(), nil
+}
+
+// ListByUser returns the user's sessions that are neither revoked nor
+// expired, most recently seen first.
+func (s *PostgresSessionStore) ListByUser(ctx context.Context, userID uuid.UUID) ([]*Session, error) {
+	var models []sessionModel
+	err := s.db.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now().UTC()).
+		Order("last_seen_at DESC").
+		Find(&models).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	sessions := make([]*Session, 0, len(models))
+	for i := range models {
+		sessions = append(sessions, models[i].toSession())
+	}
+	return sessions, nil
+}
+
+// Touch records activity on a session and moves its expiry forward to expiresAt.
+func (s *PostgresSessionStore) Touch(ctx context.Context, id uuid.UUID, device DeviceInfo, at, expiresAt time.Time) error {
+	result := s.db.WithContext(ctx).Model(&sessionModel{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"user_agent":   device.UserAgent,
+		"ip":           device.IP,
+		"platform":     device.Platform,
+		"last_seen_at": at,
+		"expires_at":   gorm.Expr("GREATEST(expires_at, ?)", expiresAt.UTC()),
+	})
+	if result.Error != nil {
+		return fmt.Errorf("failed to touch session: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// Revoke revokes a single session.
+func (s *PostgresSessionStore) Revoke(ctx context.Context, id uuid.UUID) error {
+	result := s.db.WithContext(ctx).Model(&sessionModel{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now().UTC())
+	if result.Error != nil {
+		return fmt.Errorf("failed to revoke session: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		if _, err := s.Get(ctx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RevokeByUser revokes every session belonging to a user.
+func (s *PostgresSessionStore) RevokeByUser(ctx context.Context, userID uuid.UUID) error {
+	err := s.db.WithContext(ctx).Model(&sessionModel{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now().UTC()).Error
+	if err != nil {
+		return fmt.Errorf("failed to revoke user sessions: %w", err)
+	}
+	return nil
+}
+
+// DeleteRevoked removes sessions revoked or expired before the given time.
+func (s *PostgresSessionStore) DeleteRevoked(ctx context.Context, before time.Time) (int64, error) {
+	result := s.db.WithContext(ctx).Where("revoked_at < ? OR expires_at < ?", before, before).Delete(&sessionModel{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete revoked sessions: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
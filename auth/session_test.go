@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func newSessionManager(refreshExpiry time.Duration) (*SessionManager, *MemorySessionStore) {
+	m := NewJWTManager("test-secret", time.Minute, refreshExpiry)
+	store := NewMemorySessionStore()
+	return NewSessionManager(NewRefreshTokenService(m, NewMemoryRefreshTokenStore()), store), store
+}
+
+func TestSessionExpiresWithRefreshFamily(t *testing.T) {
+	ctx := context.Background()
+	sessions, store := newSessionManager(time.Hour)
+	userID := uuid.New()
+
+	pair, err := sessions.Login(ctx, userID, "owner@example.com", RoleOwner, DeviceInfo{Platform: "ios"})
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	session, err := store.Get(ctx, pair.FamilyID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if !session.ExpiresAt.Equal(pair.RefreshExpiresAt) {
+		t.Fatalf("ExpiresAt = %v, want %v", session.ExpiresAt, pair.RefreshExpiresAt)
+	}
+
+	if err := store.Touch(ctx, pair.FamilyID, session.Device, time.Now(), time.Time{}); err != nil {
+		t.Fatalf("Touch: %v", err)
+	}
+	if got := store.sessions[pair.FamilyID].ExpiresAt; !got.Equal(pair.RefreshExpiresAt) {
+		t.Fatalf("Touch without an expiry changed ExpiresAt to %v", got)
+	}
+
+	// Expire the session in place, as if the device stopped refreshing.
+	store.sessions[pair.FamilyID].ExpiresAt = time.Now().Add(-time.Second)
+
+	list, err := sessions.List(ctx, userID)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(list) != 0 {
+		t.Fatalf("List returned %d expired sessions, want 0", len(list))
+	}
+	if _, err := sessions.Validate(ctx, pair.FamilyID, userID, session.Device); !errors.Is(err, ErrSessionExpired) {
+		t.Fatalf("Validate: got %v, want ErrSessionExpired", err)
+	}
+}
+
+func TestSessionRefreshExtendsExpiry(t *testing.T) {
+	ctx := context.Background()
+	sessions, store := newSessionManager(time.Hour)
+
+	pair, err := sessions.Login(ctx, uuid.New(), "owner@example.com", RoleOwner, DeviceInfo{})
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	store.sessions[pair.FamilyID].ExpiresAt = time.Now().Add(time.Minute)
+
+	next, err := sessions.Refresh(ctx, pair.RefreshToken, DeviceInfo{})
+	if err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	session, err := store.Get(ctx, pair.FamilyID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if !session.ExpiresAt.Equal(next.RefreshExpiresAt) {
+		t.Fatalf("ExpiresAt = %v, want %v", session.ExpiresAt, next.RefreshExpiresAt)
+	}
+}
+
+func TestSessionRevokeUser(t *testing.T) {
+	ctx := context.Background()
+	sessions, _ := newSessionManager(time.Hour)
+	userID := uuid.New()
+
+	var pairs []*TokenPair
+	for i := 0; i < 2; i++ {
+		pair, err := sessions.Login(ctx, userID, "owner@example.com", RoleOwner, DeviceInfo{})
+		if err != nil {
+			t.Fatalf("Login: %v", err)
+		}
+		pairs = append(pairs, pair)
+	}
+
+	if err := sessions.RevokeUser(ctx, userID, RevokeReasonAdmin); err != nil {
+		t.Fatalf("RevokeUser: %v", err)
+	}
+	list, err := sessions.List(ctx, userID)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(list) != 0 {
+		t.Fatalf("List returned %d sessions after RevokeUser, want 0", len(list))
+	}
+	for _, pair := range pairs {
+		if _, err := sessions.Validate(ctx, pair.FamilyID, userID, DeviceInfo{}); !errors.Is(err, ErrSessionRevoked) {
+			t.Fatalf("Validate: got %v, want ErrSessionRevoked", err)
+		}
+		if _, err := sessions.Refresh(ctx, pair.RefreshToken, DeviceInfo{}); !errors.Is(err, ErrTokenFamilyRevoked) {
+			t.Fatalf("Refresh: got %v, want ErrTokenFamilyRevoked", err)
+		}
+	}
+}
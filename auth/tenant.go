@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// TenantRole is a user's role within one shop or organisation.
+type TenantRole string
+
+const (
+	TenantRoleOwner   TenantRole = "owner"
+	TenantRoleManager TenantRole = "manager"
+	TenantRoleStaff   TenantRole = "staff"
+)
+
+// TenantClaim scopes a token to one tenant and records the user's role there.
+type TenantClaim struct {
+	ID   uuid.UUID  `json:"id"`
+	Role TenantRole `json:"role"`
+}
+
+// WithTenant scopes an access token to a tenant.
+func WithTenant(tenantID uuid.UUID, role TenantRole) TokenOption {
+	return func(c *Claims) {
+		c.Tenant = &TenantClaim{ID: tenantID, Role: role}
+	}
+}
+
+// TenantID returns the tenant the token is scoped to, if any.
+func (c *Claims) TenantID() (uuid.UUID, bool) {
+	if c.Tenant == nil {
+		return uuid.UUID{}, false
+	}
+	return c.Tenant.ID, true
+}
+
+// RescopeAccessToken issues a new access token for the same user and session
+// scoped to another tenant, for when a user switches shop. Passing uuid.Nil
+// drops the tenant scope. The caller must check the user's membership in the
+// tenant before calling this. Access tokens from a refresh carry the tenant
+// the session was issued with, so clients re-scope again after refreshing.
+// The new token expires no later than the one it replaces, so re-scoping
+// cannot extend access without going through a refresh.
+func (m *JWTManager) RescopeAccessToken(claims *Claims, tenantID uuid.UUID, role TenantRole) (string, error) {
+	if claims.TokenType != AccessToken || claims.IsImpersonated() {
+		return "", fmt.Errorf("%w: only user access tokens can be re-scoped", ErrWrongTokenType)
+	}
+
+	opts := []TokenOption{func(c *Claims) {
+		c.Scope = claims.Scope
+		c.MFAAt = claims.MFAAt
+		if claims.ExpiresAt != nil && c.ExpiresAt.After(claims.ExpiresAt.Time) {
+			c.ExpiresAt = claims.ExpiresAt
+		}
+	}}
+	if tenantID != uuid.Nil {
+		opts = append(opts, WithTenant(tenantID, role))
+	}
+	token, _, err := m.generateAccessToken(claims.UserID, claims.Email, claims.Role, claims.FamilyID, opts...)
+	return token, err
+}
@@ -0,0 +1,56 @@
+package totp
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// recoveryAlphabet omits characters that are easy to confuse when read aloud.
+const recoveryAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+
+// GenerateRecoveryCodes returns n one-time recovery codes formatted as
+// "xxxxx-xxxxx" together with their hashes. Show the codes to the user once
+// and store only the hashes.
+func GenerateRecoveryCodes(n int) (codes []string, hashes []string, err error) {
+	codes = make([]string, 0, n)
+	hashes = make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		b := make([]byte, 10)
+		for j := range b {
+			idx, err := rand.Int(rand.Reader, big.NewInt(int64(len(recoveryAlphabet))))
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to generate recovery code: %w", err)
+			}
+			b[j] = recoveryAlphabet[idx.Int64()]
+		}
+		code := string(b[:5]) + "-" + string(b[5:])
+		codes = append(codes, code)
+		hashes = append(hashes, HashRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
+
+// HashRecoveryCode normalises and hashes a recovery code for storage.
+func HashRecoveryCode(code string) string {
+	normalised := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	sum := sha256.Sum256([]byte(normalised))
+	return hex.EncodeToString(sum[:])
+}
+
+// MatchRecoveryCode returns the index of the stored hash matching code, or
+// -1. The caller must delete the matched hash so the code cannot be reused.
+func MatchRecoveryCode(code string, hashes []string) int {
+	hash := HashRecoveryCode(code)
+	match := -1
+	for i, h := range hashes {
+		if subtle.ConstantTimeCompare([]byte(h), []byte(hash)) == 1 {
+			match = i
+		}
+	}
+	return match
+}
@@ -0,0 +1,28 @@
+package totp
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryReplayStore is an in-process ReplayStore.
+type MemoryReplayStore struct {
+	mu       sync.Mutex
+	lastStep map[string]int64
+}
+
+// NewMemoryReplayStore creates an empty in-memory replay store.
+func NewMemoryReplayStore() *MemoryReplayStore {
+	return &MemoryReplayStore{lastStep: make(map[string]int64)}
+}
+
+// MarkUsed records step for accountID unless it is not newer than the last one.
+func (s *MemoryReplayStore) MarkUsed(_ context.Context, accountID string, step int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if last, ok := s.lastStep[accountID]; ok && step <= last {
+		return ErrCodeReused
+	}
+	s.lastStep[accountID] = step
+	return nil
+}
@@ -0,0 +1,183 @@
+package totp
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// TOTP errors.
+var (
+	ErrInvalidCode   = errors.New("invalid verification code")
+	ErrCodeReused    = errors.New("verification code has already been used")
+	ErrInvalidSecret = errors.New("invalid TOTP secret")
+	ErrInvalidConfig = errors.New("invalid TOTP config")
+)
+
+var b32 = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// Config holds RFC 6238 parameters. The defaults (6 digits, 30 second period,
+// SHA-1) are what every mainstream authenticator app expects.
+type Config struct {
+	Digits int
+	Period time.Duration
+	// Skew is the number of periods before and after now that are accepted,
+	// to allow for clock drift between the server and the phone.
+	Skew int
+}
+
+// DefaultConfig returns 6 digits, a 30 second period and one step of drift.
+func DefaultConfig() Config {
+	return Config{Digits: 6, Period: 30 * time.Second, Skew: 1}
+}
+
+// check rejects parameters that RFC 4226 and RFC 6238 do not allow.
+func (c Config) check() error {
+	switch {
+	case c.Digits < 6 || c.Digits > 8:
+		return fmt.Errorf("%w: digits must be between 6 and 8, got %d", ErrInvalidConfig, c.Digits)
+	case c.Period < time.Second || c.Period%time.Second != 0:
+		return fmt.Errorf("%w: period must be a whole number of seconds, got %s", ErrInvalidConfig, c.Period)
+	case c.Skew < 0:
+		return fmt.Errorf("%w: skew must not be negative, got %d", ErrInvalidConfig, c.Skew)
+	}
+	return nil
+}
+
+// GenerateSecret returns a new random 160-bit secret, base32 encoded.
+func GenerateSecret() (string, error) {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate TOTP secret: %w", err)
+	}
+	return b32.EncodeToString(b), nil
+}
+
+// ProvisioningURI builds the otpauth:// URI shown as a QR code during enrolment.
+func (c Config) ProvisioningURI(secret, issuer, accountName string) string {
+	v := url.Values{}
+	v.Set("secret", secret)
+	v.Set("issuer", issuer)
+	v.Set("algorithm", "SHA1")
+	v.Set("digits", fmt.Sprintf("%d", c.Digits))
+	v.Set("period", fmt.Sprintf("%d", int(c.Period/time.Second)))
+
+	u := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + issuer + ":" + accountName,
+		RawQuery: v.Encode(),
+	}
+	return u.String()
+}
+
+// Code returns the code for the given time.
+func (c Config) Code(secret string, at time.Time) (string, error) {
+	if err := c.check(); err != nil {
+		return "", err
+	}
+	key, err := decodeSecret(secret)
+	if err != nil {
+		return "", err
+	}
+	return c.codeAt(key, c.step(at)), nil
+}
+
+// Validate checks the code against every step within the skew window and
+// returns the matching time step.
+func (c Config) Validate(secret, code string, at time.Time) (int64, error) {
+	if err := c.check(); err != nil {
+		return 0, err
+	}
+	key, err := decodeSecret(secret)
+	if err != nil {
+		return 0, err
+	}
+	code = strings.TrimSpace(code)
+	if len(code) != c.Digits {
+		return 0, ErrInvalidCode
+	}
+
+	now := c.step(at)
+	for i := -c.Skew; i <= c.Skew; i++ {
+		step := now + int64(i)
+		if subtle.ConstantTimeCompare([]byte(c.codeAt(key, step)), []byte(code)) == 1 {
+			return step, nil
+		}
+	}
+	return 0, ErrInvalidCode
+}
+
+// step returns the RFC 6238 time step counter for t.
+func (c Config) step(t time.Time) int64 {
+	return t.Unix() / int64(c.Period/time.Second)
+}
+
+// codeAt computes the HOTP value (RFC 4226) for a counter.
+func (c Config) codeAt(key []byte, counter int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(counter))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < c.Digits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", c.Digits, value%mod)
+}
+
+// decodeSecret accepts base32 secrets with or without padding, spaces or lowercase.
+func decodeSecret(secret string) ([]byte, error) {
+	s := strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+	s = strings.TrimRight(s, "=")
+	key, err := b32.DecodeString(s)
+	if err != nil || len(key) == 0 {
+		return nil, ErrInvalidSecret
+	}
+	return key, nil
+}
+
+// ReplayStore remembers the last time step accepted per account so a code
+// cannot be used twice.
+type ReplayStore interface {
+	// MarkUsed records step for accountID and returns ErrCodeReused if step
+	// is not newer than the last recorded step.
+	MarkUsed(ctx context.Context, accountID string, step int64) error
+}
+
+// Verifier validates codes and blocks replay of a code already used.
+type Verifier struct {
+	config Config
+	store  ReplayStore
+}
+
+// NewVerifier creates a verifier backed by the given replay store.
+func NewVerifier(config Config, store ReplayStore) (*Verifier, error) {
+	if err := config.check(); err != nil {
+		return nil, err
+	}
+	return &Verifier{config: config, store: store}, nil
+}
+
+// Verify checks the code for the account and marks its time step as used.
+func (v *Verifier) Verify(ctx context.Context, accountID, secret, code string) error {
+	step, err := v.config.Validate(secret, code, time.Now())
+	if err != nil {
+		return err
+	}
+	return v.store.MarkUsed(ctx, accountID, step)
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -63,18 +64,102 @@ func LoadKafkaConfig(v *viper.Viper) KafkaConfig {
 
 // JWTConfig holds JWT-specific configuration.
 type JWTConfig struct {
-	Secret        string
-	AccessExpiry  string
-	RefreshExpiry string
+	Secret            string
+	AccessExpiry      string
+	RefreshExpiry     string
+	KeyID             string
+	PrivateKeyFile    string
+	PublicKeys        []JWTKeyConfig
+	RetiredKeys       []JWTKeyConfig
+	JWKSURL           string
+	JWKSRefresh       string
+	Issuer            string
+	ExpectedIssuers   []string
+	Audiences         []string
+	ExpectedAudiences []string
+	Leeway            string
+}
+
+// JWTKeyConfig describes a retired JWT key that still verifies until NotAfter.
+// Source is either "file:<path>" pointing at a PEM key or "base64:<secret>"
+// holding a standard base64 encoded HMAC secret.
+type JWTKeyConfig struct {
+	ID       string
+	Source   string
+	NotAfter string
 }
 
 // LoadJWTConfig extracts JWT config from Viper.
+// JWT_PUBLIC_KEYS is a comma-separated list of "[kid=]file:<path>" PEM public
+// keys for services that only validate tokens. Keys listed without a kid
+// use their RFC 7638 thumbprint.
+// JWT_RETIRED_KEYS is a comma-separated list of "kid=source@not-after" entries,
+// e.g. "2025-01=file:/etc/jwt/2025-01.pem@2025-02-01T00:00:00Z" or
+// "old=base64:c2VjcmV0". The @not-after suffix is optional.
 func LoadJWTConfig(v *viper.Viper) JWTConfig {
 	return JWTConfig{
-		Secret:        v.GetString("JWT_SECRET"),
-		AccessExpiry:  v.GetString("JWT_ACCESS_EXPIRY"),
-		RefreshExpiry: v.GetString("JWT_REFRESH_EXPIRY"),
+		Secret:            v.GetString("JWT_SECRET"),
+		AccessExpiry:      v.GetString("JWT_ACCESS_EXPIRY"),
+		RefreshExpiry:     v.GetString("JWT_REFRESH_EXPIRY"),
+		KeyID:             v.GetString("JWT_KEY_ID"),
+		PrivateKeyFile:    v.GetString("JWT_PRIVATE_KEY_FILE"),
+		PublicKeys:        parsePublicKeys(v.GetString("JWT_PUBLIC_KEYS")),
+		RetiredKeys:       parseJWTKeys(v.GetString("JWT_RETIRED_KEYS")),
+		JWKSURL:           v.GetString("JWT_JWKS_URL"),
+		JWKSRefresh:       v.GetString("JWT_JWKS_REFRESH"),
+		Issuer:            v.GetString("JWT_ISSUER"),
+		ExpectedIssuers:   splitList(v.GetString("JWT_EXPECTED_ISSUERS")),
+		Audiences:         splitList(v.GetString("JWT_AUDIENCES")),
+		ExpectedAudiences: splitList(v.GetString("JWT_EXPECTED_AUDIENCES")),
+		Leeway:            v.GetString("JWT_LEEWAY"),
+	}
+}
+
+// splitList splits a comma-separated value, dropping empty entries.
+func splitList(s string) []string {
+	var out []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// parseJWTKeys splits a JWT_RETIRED_KEYS value into key entries.
+func parseJWTKeys(s string) []JWTKeyConfig {
+	var keys []JWTKeyConfig
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		var kc JWTKeyConfig
+		kc.ID, entry, _ = strings.Cut(entry, "=")
+		// Only split off a suffix that is a timestamp, so "@" may appear in file paths.
+		if i := strings.LastIndex(entry, "@"); i >= 0 {
+			if _, err := time.Parse(time.RFC3339, entry[i+1:]); err == nil {
+				entry, kc.NotAfter = entry[:i], entry[i+1:]
+			}
+		}
+		kc.Source = entry
+		keys = append(keys, kc)
+	}
+	return keys
+}
+
+// parsePublicKeys splits a JWT_PUBLIC_KEYS value into key entries.
+func parsePublicKeys(s string) []JWTKeyConfig {
+	var keys []JWTKeyConfig
+	for _, entry := range splitList(s) {
+		var kc JWTKeyConfig
+		if !strings.HasPrefix(entry, "file:") {
+			kc.ID, entry, _ = strings.Cut(entry, "=")
+		}
+		kc.Source = entry
+		keys = append(keys, kc)
 	}
+	return keys
 }
 
 // GetAppEnv returns the current application environment.
@@ -0,0 +1,101 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/reqctx"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// GormLogger is a GORM logger that writes to Zap. Queries run with
+// db.WithContext(ctx) are logged with the request-scoped logger carried by
+// ctx, so they include the request ID and user.
+type GormLogger struct {
+	logger        *zap.Logger
+	level         gormlogger.LogLevel
+	slowThreshold time.Duration
+}
+
+// NewGormLogger creates a GORM logger that logs errors and queries slower
+// than 200ms.
+func NewGormLogger(logger *zap.Logger) *GormLogger {
+	return &GormLogger{
+		logger:        logger.Named("gorm"),
+		level:         gormlogger.Warn,
+		slowThreshold: 200 * time.Millisecond,
+	}
+}
+
+// LogMode returns a copy of the logger with the given level.
+func (l *GormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	copied := *l
+	copied.level = level
+	return &copied
+}
+
+// Info logs an informational message.
+func (l *GormLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= gormlogger.Info {
+		l.from(ctx).Info(fmt.Sprintf(msg, data...))
+	}
+}
+
+// Warn logs a warning.
+func (l *GormLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= gormlogger.Warn {
+		l.from(ctx).Warn(fmt.Sprintf(msg, data...))
+	}
+}
+
+// Error logs an error.
+func (l *GormLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= gormlogger.Error {
+		l.from(ctx).Error(fmt.Sprintf(msg, data...))
+	}
+}
+
+// Trace logs a finished query. Record-not-found errors are not logged.
+func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= gormlogger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && l.level >= gormlogger.Error:
+		sql, rows := fc()
+		l.from(ctx).Error("query failed",
+			zap.String("sql", sql),
+			zap.Int64("rows", rows),
+			zap.Duration("elapsed", elapsed),
+			zap.Error(err),
+		)
+	case l.slowThreshold > 0 && elapsed > l.slowThreshold && l.level >= gormlogger.Warn:
+		sql, rows := fc()
+		l.from(ctx).Warn("slow query",
+			zap.String("sql", sql),
+			zap.Int64("rows", rows),
+			zap.Duration("elapsed", elapsed),
+		)
+	case l.level >= gormlogger.Info:
+		sql, rows := fc()
+		l.from(ctx).Debug("query",
+			zap.String("sql", sql),
+			zap.Int64("rows", rows),
+			zap.Duration("elapsed", elapsed),
+		)
+	}
+}
+
+// from returns the request-scoped logger for ctx.
+func (l *GormLogger) from(ctx context.Context) *zap.Logger {
+	if logger, ok := reqctx.LoggerFrom(ctx); ok {
+		return logger.Named("gorm")
+	}
+	return reqctx.Logger(ctx, l.logger)
+}
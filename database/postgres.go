@@ -7,7 +7,6 @@ import (
 	"go.uber.org/zap"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	gormlogger "gorm.io/gorm/logger"
 )
 
 // PostgresConfig holds database connection configuration.
@@ -31,7 +30,7 @@ func (c PostgresConfig) DSN() string {
 // Connect establishes a connection to PostgreSQL with GORM.
 func Connect(config PostgresConfig, logger *zap.Logger) (*gorm.DB, error) {
 	gormConfig := &gorm.Config{
-		Logger: gormlogger.Default.LogMode(gormlogger.Warn),
+		Logger: NewGormLogger(logger),
 	}
 
 	db, err := gorm.Open(postgres.Open(config.DSN()), gormConfig)
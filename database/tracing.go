@@ -0,0 +1,88 @@
+package database
+
+import (
+	"errors"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/tracing"
+	"gorm.io/gorm"
+)
+
+// tracingSpanKey is the statement setting holding a query's span.
+const tracingSpanKey = "tracing:span"
+
+// RegisterTracing adds GORM callbacks that wrap each query in a client span.
+// Queries must run with db.WithContext(ctx) to join the request's trace.
+func RegisterTracing(db *gorm.DB, tracer *tracing.Tracer) error {
+	cb := db.Callback()
+	if err := cb.Create().Before("gorm:create").Register("tracing:before_create", beforeQuery(tracer, "create")); err != nil {
+		return err
+	}
+	if err := cb.Create().After("gorm:create").Register("tracing:after_create", afterQuery); err != nil {
+		return err
+	}
+	if err := cb.Query().Before("gorm:query").Register("tracing:before_query", beforeQuery(tracer, "select")); err != nil {
+		return err
+	}
+	if err := cb.Query().After("gorm:query").Register("tracing:after_query", afterQuery); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register("tracing:before_update", beforeQuery(tracer, "update")); err != nil {
+		return err
+	}
+	if err := cb.Update().After("gorm:update").Register("tracing:after_update", afterQuery); err != nil {
+		return err
+	}
+	if err := cb.Delete().Before("gorm:delete").Register("tracing:before_delete", beforeQuery(tracer, "delete")); err != nil {
+		return err
+	}
+	if err := cb.Delete().After("gorm:delete").Register("tracing:after_delete", afterQuery); err != nil {
+		return err
+	}
+	if err := cb.Row().Before("gorm:row").Register("tracing:before_row", beforeQuery(tracer, "row")); err != nil {
+		return err
+	}
+	if err := cb.Row().After("gorm:row").Register("tracing:after_row", afterQuery); err != nil {
+		return err
+	}
+	if err := cb.Raw().Before("gorm:raw").Register("tracing:before_raw", beforeQuery(tracer, "raw")); err != nil {
+		return err
+	}
+	return cb.Raw().After("gorm:raw").Register("tracing:after_raw", afterQuery)
+}
+
+// beforeQuery starts a span for the statement.
+func beforeQuery(tracer *tracing.Tracer, operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		if db.Statement.Context == nil {
+			return
+		}
+		_, span := tracer.Start(db.Statement.Context, "db."+operation, tracing.SpanKindClient)
+		if span == nil {
+			return
+		}
+		span.SetAttribute("db.system", "postgresql")
+		span.SetAttribute("db.operation", operation)
+		db.InstanceSet(tracingSpanKey, span)
+	}
+}
+
+// afterQuery finishes the statement's span.
+func afterQuery(db *gorm.DB) {
+	val, ok := db.InstanceGet(tracingSpanKey)
+	if !ok {
+		return
+	}
+	span, ok := val.(*tracing.Span)
+	if !ok {
+		return
+	}
+	if db.Statement.Table != "" {
+		span.SetAttribute("db.table", db.Statement.Table)
+	}
+	span.SetAttribute("db.statement", db.Statement.SQL.String())
+	span.SetAttribute("db.rows_affected", db.Statement.RowsAffected)
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(db.Error)
+	}
+	span.End()
+}
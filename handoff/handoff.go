@@ -0,0 +1,345 @@
+package handoff
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/domain"
+	"github.com/google/uuid"
+)
+
+// Handoff errors.
+var (
+	ErrChallengeNotFound = errors.New("handoff challenge not found")
+	ErrChallengeExpired  = errors.New("handoff code has expired")
+	ErrChallengeUsed     = errors.New("handoff code has already been used")
+	ErrInvalidCode       = errors.New("invalid handoff code")
+	ErrTooManyAttempts   = errors.New("too many handoff verification attempts")
+	ErrWrongRunner       = errors.New("handoff is assigned to a different runner")
+)
+
+// EventHandoffVerified is the domain event type emitted on successful verification.
+const EventHandoffVerified = "handoff.verified"
+
+// Stage is the point in a booking where the pet changes hands.
+type Stage string
+
+const (
+	StagePickup  Stage = "pickup"
+	StageDropoff Stage = "dropoff"
+)
+
+// Method is how a handoff was verified.
+type Method string
+
+const (
+	MethodPIN Method = "pin"
+	MethodQR  Method = "qr"
+)
+
+// Challenge is an outstanding single-use handoff code for one booking stage.
+type Challenge struct {
+	ID          uuid.UUID
+	BookingID   uuid.UUID
+	RunnerID    uuid.UUID
+	Stage       Stage
+	PINHash     string
+	Attempts    int
+	MaxAttempts int
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+	UsedAt      *time.Time
+}
+
+// Store persists handoff challenges.
+type Store interface {
+	// Save stores a challenge, replacing any earlier one for the same booking and stage.
+	Save(ctx context.Context, challenge *Challenge) error
+	// Get returns the current challenge for a booking stage or ErrChallengeNotFound.
+	Get(ctx context.Context, bookingID uuid.UUID, stage Stage) (*Challenge, error)
+	// GetByID returns a challenge by ID or ErrChallengeNotFound.
+	GetByID(ctx context.Context, id uuid.UUID) (*Challenge, error)
+	// IncrementAttempts atomically counts a PIN attempt and returns the new total.
+	IncrementAttempts(ctx context.Context, id uuid.UUID) (int, error)
+	// MarkUsed atomically consumes the challenge, returning ErrChallengeUsed
+	// if it already was or ErrTooManyAttempts if more than MaxAttempts PIN
+	// attempts were counted.
+	MarkUsed(ctx context.Context, id uuid.UUID, at time.Time) error
+}
+
+// Config holds handoff code settings.
+type Config struct {
+	TTL         time.Duration
+	MaxAttempts int
+	PINLength   int
+}
+
+// DefaultConfig returns a 15 minute lifetime, five attempts and six-digit PINs.
+func DefaultConfig() Config {
+	return Config{TTL: 15 * time.Minute, MaxAttempts: 5, PINLength: 6}
+}
+
+// WithDefaults returns c with every unset or non-positive field taken from
+// DefaultConfig.
+func (c Config) WithDefaults() Config {
+	def := DefaultConfig()
+	if c.TTL <= 0 {
+		c.TTL = def.TTL
+	}
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = def.MaxAttempts
+	}
+	if c.PINLength <= 0 {
+		c.PINLength = def.PINLength
+	}
+	return c
+}
+
+// Issued is a newly issued challenge. PIN is shown to the person handing
+// over the pet and QRPayload can be rendered as a QR code instead.
+type Issued struct {
+	ChallengeID uuid.UUID `json:"challenge_id"`
+	PIN         string    `json:"pin"`
+	QRPayload   string    `json:"qr_payload"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// Verification is the result of a successful handoff verification.
+type Verification struct {
+	ChallengeID uuid.UUID
+	BookingID   uuid.UUID
+	RunnerID    uuid.UUID
+	Stage       Stage
+	Method      Method
+	VerifiedAt  time.Time
+}
+
+// Event returns the handoff.verified domain event for the booking aggregate.
+func (v *Verification) Event(version int64) domain.DomainEvent {
+	return domain.NewDomainEvent(EventHandoffVerified, v.BookingID, version, map[string]interface{}{
+		"challenge_id": v.ChallengeID.String(),
+		"booking_id":   v.BookingID.String(),
+		"runner_id":    v.RunnerID.String(),
+		"stage":        string(v.Stage),
+		"method":       string(v.Method),
+		"verified_at":  v.VerifiedAt,
+	})
+}
+
+// AddTo appends the handoff.verified event to the booking aggregate.
+func (v *Verification) AddTo(ar *domain.AggregateRoot) {
+	ar.AddDomainEvent(v.Event(ar.Version))
+}
+
+// qrPayload is the signed content of a handoff QR code.
+type qrPayload struct {
+	ChallengeID uuid.UUID `json:"cid"`
+	BookingID   uuid.UUID `json:"bid"`
+	RunnerID    uuid.UUID `json:"rid"`
+	Stage       Stage     `json:"stg"`
+	ExpiresAt   int64     `json:"exp"`
+}
+
+// Service issues and verifies handoff codes.
+type Service struct {
+	store  Store
+	secret []byte
+	config Config
+}
+
+// NewService creates a handoff service. secret signs QR payloads and keys
+// the PIN hashes, so it must be shared by every replica. Unset config fields
+// fall back to DefaultConfig.
+func NewService(store Store, secret []byte, config Config) *Service {
+	return &Service{store: store, secret: secret, config: config.WithDefaults()}
+}
+
+// Issue creates a new challenge for a booking stage, invalidating any earlier one.
+func (s *Service) Issue(ctx context.Context, bookingID, runnerID uuid.UUID, stage Stage) (*Issued, error) {
+	pin, err := randomPIN(s.config.PINLength)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	challenge := &Challenge{
+		ID:          uuid.New(),
+		BookingID:   bookingID,
+		RunnerID:    runnerID,
+		Stage:       stage,
+		MaxAttempts: s.config.MaxAttempts,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(s.config.TTL),
+	}
+	challenge.PINHash = s.hashPIN(challenge.ID, pin)
+
+	qr, err := s.signQR(qrPayload{
+		ChallengeID: challenge.ID,
+		BookingID:   bookingID,
+		RunnerID:    runnerID,
+		Stage:       stage,
+		ExpiresAt:   challenge.ExpiresAt.Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.store.Save(ctx, challenge); err != nil {
+		return nil, fmt.Errorf("failed to save handoff challenge: %w", err)
+	}
+	return &Issued{
+		ChallengeID: challenge.ID,
+		PIN:         pin,
+		QRPayload:   qr,
+		ExpiresAt:   challenge.ExpiresAt,
+	}, nil
+}
+
+// VerifyPIN checks a PIN entered for a booking stage by the given runner.
+// Every attempt, including the correct one, is counted before the PIN is
+// compared so concurrent guesses cannot get past MaxAttempts.
+func (s *Service) VerifyPIN(ctx context.Context, bookingID, runnerID uuid.UUID, stage Stage, pin string) (*Verification, error) {
+	challenge, err := s.store.Get(ctx, bookingID, stage)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkUsable(challenge, runnerID); err != nil {
+		return nil, err
+	}
+
+	attempts, err := s.store.IncrementAttempts(ctx, challenge.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record handoff attempt: %w", err)
+	}
+	if attempts > challenge.MaxAttempts {
+		return nil, ErrTooManyAttempts
+	}
+
+	expected := s.hashPIN(challenge.ID, strings.TrimSpace(pin))
+	if !hmac.Equal([]byte(expected), []byte(challenge.PINHash)) {
+		if attempts >= challenge.MaxAttempts {
+			return nil, ErrTooManyAttempts
+		}
+		return nil, ErrInvalidCode
+	}
+
+	return s.consume(ctx, challenge, MethodPIN)
+}
+
+// VerifyQR checks a scanned QR payload for the given runner.
+func (s *Service) VerifyQR(ctx context.Context, payload string, runnerID uuid.UUID) (*Verification, error) {
+	qr, err := s.parseQR(payload)
+	if err != nil {
+		return nil, err
+	}
+	if time.Now().Unix() > qr.ExpiresAt {
+		return nil, ErrChallengeExpired
+	}
+
+	challenge, err := s.store.GetByID(ctx, qr.ChallengeID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkUsable(challenge, runnerID); err != nil {
+		return nil, err
+	}
+	return s.consume(ctx, challenge, MethodQR)
+}
+
+// checkUsable rejects challenges that are used, expired, locked or for another runner.
+func (s *Service) checkUsable(challenge *Challenge, runnerID uuid.UUID) error {
+	switch {
+	case challenge.UsedAt != nil:
+		return ErrChallengeUsed
+	case time.Now().After(challenge.ExpiresAt):
+		return ErrChallengeExpired
+	case challenge.Attempts >= challenge.MaxAttempts:
+		return ErrTooManyAttempts
+	case challenge.RunnerID != runnerID:
+		return ErrWrongRunner
+	}
+	return nil
+}
+
+// consume marks the challenge used and builds the verification result.
+func (s *Service) consume(ctx context.Context, challenge *Challenge, method Method) (*Verification, error) {
+	now := time.Now().UTC()
+	if err := s.store.MarkUsed(ctx, challenge.ID, now); err != nil {
+		return nil, err
+	}
+	return &Verification{
+		ChallengeID: challenge.ID,
+		BookingID:   challenge.BookingID,
+		RunnerID:    challenge.RunnerID,
+		Stage:       challenge.Stage,
+		Method:      method,
+		VerifiedAt:  now,
+	}, nil
+}
+
+// hashPIN keys the PIN hash with the service secret so a leaked table
+// cannot be brute-forced offline.
+func (s *Service) hashPIN(challengeID uuid.UUID, pin string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write(challengeID[:])
+	mac.Write([]byte(pin))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// signQR encodes the payload as "<base64url json>.<base64url hmac>".
+func (s *Service) signQR(p qrPayload) (string, error) {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode QR payload: %w", err)
+	}
+	body := base64.RawURLEncoding.EncodeToString(data)
+	return body + "." + base64.RawURLEncoding.EncodeToString(s.mac(body)), nil
+}
+
+// parseQR verifies the signature and decodes the payload.
+func (s *Service) parseQR(payload string) (*qrPayload, error) {
+	body, sig, ok := strings.Cut(payload, ".")
+	if !ok {
+		return nil, ErrInvalidCode
+	}
+	got, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(got, s.mac(body)) {
+		return nil, ErrInvalidCode
+	}
+	data, err := base64.RawURLEncoding.DecodeString(body)
+	if err != nil {
+		return nil, ErrInvalidCode
+	}
+	var p qrPayload
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, ErrInvalidCode
+	}
+	return &p, nil
+}
+
+func (s *Service) mac(body string) []byte {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte("handoff-qr:"))
+	mac.Write([]byte(body))
+	return mac.Sum(nil)
+}
+
+// randomPIN returns a uniformly random numeric PIN.
+func randomPIN(length int) (string, error) {
+	max := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(length)), nil)
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate handoff PIN: %w", err)
+	}
+	return fmt.Sprintf("%0*d", length, n), nil
+}
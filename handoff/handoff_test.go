@@ -0,0 +1,81 @@
+package handoff
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestConfigWithDefaults(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		want Config
+	}{
+		{"zero", Config{}, DefaultConfig()},
+		{"negative", Config{TTL: -time.Minute, MaxAttempts: -1, PINLength: -4}, DefaultConfig()},
+		{"set", Config{TTL: time.Minute, MaxAttempts: 3, PINLength: 4}, Config{TTL: time.Minute, MaxAttempts: 3, PINLength: 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cfg.WithDefaults(); got != tt.want {
+				t.Fatalf("WithDefaults() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServiceWithZeroConfig(t *testing.T) {
+	ctx := context.Background()
+	svc := NewService(NewMemoryStore(), []byte("test-secret"), Config{})
+	bookingID, runnerID := uuid.New(), uuid.New()
+
+	issued, err := svc.Issue(ctx, bookingID, runnerID, StagePickup)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	if len(issued.PIN) != DefaultConfig().PINLength {
+		t.Fatalf("PIN %q has length %d, want %d", issued.PIN, len(issued.PIN), DefaultConfig().PINLength)
+	}
+	if !issued.ExpiresAt.After(time.Now()) {
+		t.Fatalf("challenge issued already expired at %v", issued.ExpiresAt)
+	}
+
+	if _, err := svc.VerifyPIN(ctx, bookingID, runnerID, StagePickup, wrongPIN(issued.PIN)); !errors.Is(err, ErrInvalidCode) {
+		t.Fatalf("first wrong PIN: got %v, want ErrInvalidCode", err)
+	}
+	v, err := svc.VerifyPIN(ctx, bookingID, runnerID, StagePickup, issued.PIN)
+	if err != nil {
+		t.Fatalf("VerifyPIN: %v", err)
+	}
+	if v.Method != MethodPIN || v.BookingID != bookingID {
+		t.Fatalf("verification = %+v", v)
+	}
+}
+
+func TestServiceLocksAfterMaxAttempts(t *testing.T) {
+	ctx := context.Background()
+	svc := NewService(NewMemoryStore(), []byte("test-secret"), Config{MaxAttempts: 2})
+	bookingID, runnerID := uuid.New(), uuid.New()
+
+	issued, err := svc.Issue(ctx, bookingID, runnerID, StageDropoff)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		_, _ = svc.VerifyPIN(ctx, bookingID, runnerID, StageDropoff, wrongPIN(issued.PIN))
+	}
+	if _, err := svc.VerifyPIN(ctx, bookingID, runnerID, StageDropoff, issued.PIN); !errors.Is(err, ErrTooManyAttempts) {
+		t.Fatalf("correct PIN after lockout: got %v, want ErrTooManyAttempts", err)
+	}
+}
+
+// wrongPIN returns a PIN of the same length that differs from pin.
+func wrongPIN(pin string) string {
+	b := []byte(pin)
+	b[0] = '0' + (b[0]-'0'+1)%10
+	return string(b)
+}
@@ -0,0 +1,115 @@
+package handoff
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type stageKey struct {
+	bookingID uuid.UUID
+	stage     Stage
+}
+
+// MemoryStore is an in-process Store for tests and single-instance deployments.
+type MemoryStore struct {
+	mu         sync.Mutex
+	challenges map[uuid.UUID]*Challenge
+	current    map[stageKey]uuid.UUID
+}
+
+// NewMemoryStore creates an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		challenges: make(map[uuid.UUID]*Challenge),
+		current:    make(map[stageKey]uuid.UUID),
+	}
+}
+
+// Save stores a challenge, replacing any earlier one for the same booking and stage.
+func (s *MemoryStore) Save(_ context.Context, challenge *Challenge) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := stageKey{challenge.BookingID, challenge.Stage}
+	if prev, ok := s.current[key]; ok {
+		delete(s.challenges, prev)
+	}
+	c := *challenge
+	s.challenges[c.ID] = &c
+	s.current[key] = c.ID
+	return nil
+}
+
+// Get returns the current challenge for a booking stage.
+func (s *MemoryStore) Get(_ context.Context, bookingID uuid.UUID, stage Stage) (*Challenge, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id, ok := s.current[stageKey{bookingID, stage}]
+	if !ok {
+		return nil, ErrChallengeNotFound
+	}
+	c := *s.challenges[id]
+	return &c, nil
+}
+
+// GetByID returns a challenge by ID.
+func (s *MemoryStore) GetByID(_ context.Context, id uuid.UUID) (*Challenge, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.challenges[id]
+	if !ok {
+		return nil, ErrChallengeNotFound
+	}
+	out := *c
+	return &out, nil
+}
+
+// IncrementAttempts counts a PIN attempt and returns the new total.
+func (s *MemoryStore) IncrementAttempts(_ context.Context, id uuid.UUID) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.challenges[id]
+	if !ok {
+		return 0, ErrChallengeNotFound
+	}
+	c.Attempts++
+	return c.Attempts, nil
+}
+
+// MarkUsed consumes the challenge.
+func (s *MemoryStore) MarkUsed(_ context.Context, id uuid.UUID, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.challenges[id]
+	if !ok {
+		return ErrChallengeNotFound
+	}
+	if c.UsedAt != nil {
+		return ErrChallengeUsed
+	}
+	if c.Attempts > c.MaxAttempts {
+		return ErrTooManyAttempts
+	}
+	c.UsedAt = &at
+	return nil
+}
+
+// DeleteExpired removes challenges that expired before now.
+func (s *MemoryStore) DeleteExpired(_ context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	var n int64
+	for id, c := range s.challenges {
+		if now.After(c.ExpiresAt) {
+			delete(s.challenges, id)
+			if s.current[stageKey{c.BookingID, c.Stage}] == id {
+				delete(s.current, stageKey{c.BookingID, c.Stage})
+			}
+			n++
+		}
+	}
+	return n, nil
+}
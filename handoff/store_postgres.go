@@ -0,0 +1,158 @@
+package handoff
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// challengeModel is the GORM model for the handoff_challenges table.
+type challengeModel struct {
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey"`
+	BookingID   uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_handoff_booking_stage"`
+	Stage       string    `gorm:"not null;uniqueIndex:idx_handoff_booking_stage"`
+	RunnerID    uuid.UUID `gorm:"type:uuid;not null"`
+	PINHash     string    `gorm:"not null"`
+	Attempts    int       `gorm:"not null;default:0"`
+	MaxAttempts int       `gorm:"not null"`
+	CreatedAt   time.Time `gorm:"not null"`
+	ExpiresAt   time.Time `gorm:"not null;index"`
+	UsedAt      *time.Time
+}
+
+// TableName sets the table name for GORM.
+func (challengeModel) TableName() string { return "handoff_challenges" }
+
+func (m *challengeModel) toChallenge() *Challenge {
+	return &Challenge{
+		ID:          m.ID,
+		BookingID:   m.BookingID,
+		RunnerID:    m.RunnerID,
+		Stage:       Stage(m.Stage),
+		PINHash:     m.PINHash,
+		Attempts:    m.Attempts,
+		MaxAttempts: m.MaxAttempts,
+		CreatedAt:   m.CreatedAt,
+		ExpiresAt:   m.ExpiresAt,
+		UsedAt:      m.UsedAt,
+	}
+}
+
+// PostgresStore is a GORM-backed Store.
+type PostgresStore struct {
+	db *gorm.DB
+}
+
+// NewPostgresStore creates a Postgres handoff challenge store.
+func NewPostgresStore(db *gorm.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+// AutoMigrate creates or updates the handoff_challenges table.
+func (s *PostgresStore) AutoMigrate() error {
+	return s.db.AutoMigrate(&challengeModel{})
+}
+
+// Save stores a challenge, replacing any earlier one for the same booking and stage.
+func (s *PostgresStore) Save(ctx context.Context, challenge *Challenge) error {
+	model := challengeModel{
+		ID:          challenge.ID,
+		BookingID:   challenge.BookingID,
+		Stage:       string(challenge.Stage),
+		RunnerID:    challenge.RunnerID,
+		PINHash:     challenge.PINHash,
+		Attempts:    challenge.Attempts,
+		MaxAttempts: challenge.MaxAttempts,
+		CreatedAt:   challenge.CreatedAt,
+		ExpiresAt:   challenge.ExpiresAt,
+		UsedAt:      challenge.UsedAt,
+	}
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("booking_id = ? AND stage = ?", model.BookingID, model.Stage).
+			Delete(&challengeModel{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&model).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save handoff challenge: %w", err)
+	}
+	return nil
+}
+
+// Get returns the current challenge for a booking stage.
+func (s *PostgresStore) Get(ctx context.Context, bookingID uuid.UUID, stage Stage) (*Challenge, error) {
+	var model challengeModel
+	err := s.db.WithContext(ctx).Where("booking_id = ? AND stage = ?", bookingID, string(stage)).First(&model).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrChallengeNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get handoff challenge: %w", err)
+	}
+	return model.toChallenge(), nil
+}
+
+// GetByID returns a challenge by ID.
+func (s *PostgresStore) GetByID(ctx context.Context, id uuid.UUID) (*Challenge, error) {
+	var model challengeModel
+	err := s.db.WithContext(ctx).Where("id = ?", id).First(&model).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrChallengeNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get handoff challenge: %w", err)
+	}
+	return model.toChallenge(), nil
+}
+
+// IncrementAttempts atomically counts a PIN attempt and returns the new total.
+func (s *PostgresStore) IncrementAttempts(ctx context.Context, id uuid.UUID) (int, error) {
+	var model challengeModel
+	result := s.db.WithContext(ctx).Model(&model).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "attempts"}}}).
+		Where("id = ?", id).
+		UpdateColumn("attempts", gorm.Expr("attempts + 1"))
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to record handoff attempt: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return 0, ErrChallengeNotFound
+	}
+	return model.Attempts, nil
+}
+
+// MarkUsed atomically consumes the challenge.
+func (s *PostgresStore) MarkUsed(ctx context.Context, id uuid.UUID, at time.Time) error {
+	result := s.db.WithContext(ctx).Model(&challengeModel{}).
+		Where("id = ? AND used_at IS NULL AND attempts <= max_attempts", id).
+		Update("used_at", at)
+	if result.Error != nil {
+		return fmt.Errorf("failed to mark handoff challenge used: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		challenge, err := s.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if challenge.UsedAt == nil {
+			return ErrTooManyAttempts
+		}
+		return ErrChallengeUsed
+	}
+	return nil
+}
+
+// DeleteExpired removes challenges that have expired.
+func (s *PostgresStore) DeleteExpired(ctx context.Context) (int64, error) {
+	result := s.db.WithContext(ctx).Where("expires_at < ?", time.Now().UTC()).Delete(&challengeModel{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete expired handoff challenges: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,98 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// ErrClaimLost is returned by Complete when the caller's claim on a key was
+// taken over by another request after its lock lapsed.
+var ErrClaimLost = errors.New("idempotency key claim was lost")
+
+// HeaderKey is the request header carrying the client's idempotency key.
+const HeaderKey = "Idempotency-Key"
+
+// HeaderReplayed is set on responses replayed from the store.
+const HeaderReplayed = "Idempotent-Replayed"
+
+// Config holds idempotency settings.
+type Config struct {
+	// TTL is how long a completed response is kept for replay.
+	TTL time.Duration
+	// LockTimeout is how long an in-flight request holds its key before
+	// another request may take it over, for example after a crash.
+	LockTimeout time.Duration
+	// MaxBodyBytes caps how much of the request body is read for the fingerprint.
+	MaxBodyBytes int64
+}
+
+// DefaultConfig keeps responses for 24 hours and locks keys for one minute.
+func DefaultConfig() Config {
+	return Config{TTL: 24 * time.Hour, LockTimeout: time.Minute, MaxBodyBytes: 1 << 20}
+}
+
+// WithDefaults returns c with every unset field taken from DefaultConfig.
+func (c Config) WithDefaults() Config {
+	def := DefaultConfig()
+	if c.TTL <= 0 {
+		c.TTL = def.TTL
+	}
+	if c.LockTimeout <= 0 {
+		c.LockTimeout = def.LockTimeout
+	}
+	if c.MaxBodyBytes <= 0 {
+		c.MaxBodyBytes = def.MaxBodyBytes
+	}
+	return c
+}
+
+// Record is the stored state of an idempotency key.
+type Record struct {
+	Key         string
+	Fingerprint string
+	// ClaimToken identifies the request currently holding the key.
+	ClaimToken  string
+	Completed   bool
+	StatusCode  int
+	Header      http.Header
+	Body        []byte
+	LockedUntil time.Time
+	ExpiresAt   time.Time
+}
+
+// Response is a captured response to store for replay.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// Store persists idempotency records.
+type Store interface {
+	// Begin claims key for a new request. It returns a claim token when the
+	// caller now holds the key, or the existing record and an empty token
+	// when the key is already in flight or completed. Expired records and
+	// in-flight records whose lock has lapsed are taken over.
+	Begin(ctx context.Context, key, fingerprint string, lockedUntil time.Time) (*Record, string, error)
+	// Complete stores the response for key until expiresAt. It returns
+	// ErrClaimLost if token no longer holds the key.
+	Complete(ctx context.Context, key, token string, resp Response, expiresAt time.Time) error
+	// Release drops an in-flight claim so the request can be retried. It
+	// does nothing if token no longer holds the key.
+	Release(ctx context.Context, key, token string) error
+}
+
+// Fingerprint returns a hash identifying the request's method, path and body.
+func Fingerprint(method, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{0})
+	h.Write([]byte(path))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
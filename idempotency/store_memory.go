@@ -0,0 +1,89 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MemoryStore is an in-process Store for tests and single-instance deployments.
+type MemoryStore struct {
+	mu      sync.Mutex
+	records map[string]*Record
+}
+
+// NewMemoryStore creates an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: make(map[string]*Record)}
+}
+
+// Begin claims key for a new request.
+func (s *MemoryStore) Begin(_ context.Context, key, fingerprint string, lockedUntil time.Time) (*Record, string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if rec, ok := s.records[key]; ok && !claimable(rec, now) {
+		out := *rec
+		return &out, "", nil
+	}
+	token := uuid.NewString()
+	s.records[key] = &Record{
+		Key:         key,
+		Fingerprint: fingerprint,
+		ClaimToken:  token,
+		LockedUntil: lockedUntil,
+		ExpiresAt:   lockedUntil,
+	}
+	return nil, token, nil
+}
+
+// Complete stores the response for key.
+func (s *MemoryStore) Complete(_ context.Context, key, token string, resp Response, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, ok := s.records[key]
+	if !ok || rec.Completed || rec.ClaimToken != token {
+		return ErrClaimLost
+	}
+	rec.Completed = true
+	rec.StatusCode = resp.StatusCode
+	rec.Header = resp.Header
+	rec.Body = resp.Body
+	rec.ExpiresAt = expiresAt
+	return nil
+}
+
+// Release drops an in-flight claim.
+func (s *MemoryStore) Release(_ context.Context, key, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if rec, ok := s.records[key]; ok && !rec.Completed && rec.ClaimToken == token {
+		delete(s.records, key)
+	}
+	return nil
+}
+
+// DeleteExpired removes records that can no longer be replayed.
+func (s *MemoryStore) DeleteExpired(_ context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	var n int64
+	for key, rec := range s.records {
+		if claimable(rec, now) {
+			delete(s.records, key)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// claimable reports whether a record may be taken over by a new request.
+func claimable(rec *Record, now time.Time) bool {
+	if rec.Completed {
+		return !now.Before(rec.ExpiresAt)
+	}
+	return !now.Before(rec.LockedUntil)
+}
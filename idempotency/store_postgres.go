@@ -0,0 +1,169 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// recordModel is the GORM model for the idempotency_keys table.
+type recordModel struct {
+	Key         string    `gorm:"column:idempotency_key;primaryKey"`
+	Fingerprint string    `gorm:"not null"`
+	ClaimToken  string    `gorm:"not null;default:''"`
+	Completed   bool      `gorm:"not null;default:false"`
+	StatusCode  int       `gorm:"not null;default:0"`
+	Header      string    `gorm:"type:text;not null;default:''"`
+	Body        []byte    `gorm:"type:bytea"`
+	LockedUntil time.Time `gorm:"not null"`
+	ExpiresAt   time.Time `gorm:"not null;index"`
+}
+
+// TableName sets the table name for GORM.
+func (recordModel) TableName() string { return "idempotency_keys" }
+
+func (m *recordModel) toRecord() (*Record, error) {
+	rec := &Record{
+		Key:         m.Key,
+		Fingerprint: m.Fingerprint,
+		ClaimToken:  m.ClaimToken,
+		Completed:   m.Completed,
+		StatusCode:  m.StatusCode,
+		Body:        m.Body,
+		LockedUntil: m.LockedUntil,
+		ExpiresAt:   m.ExpiresAt,
+	}
+	if m.Header != "" {
+		if err := json.Unmarshal([]byte(m.Header), &rec.Header); err != nil {
+			return nil, fmt.Errorf("failed to decode stored headers: %w", err)
+		}
+	}
+	return rec, nil
+}
+
+// PostgresStore is a GORM-backed Store.
+type PostgresStore struct {
+	db *gorm.DB
+}
+
+// NewPostgresStore creates a Postgres idempotency store.
+func NewPostgresStore(db *gorm.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+// AutoMigrate creates or updates the idempotency_keys table.
+func (s *PostgresStore) AutoMigrate() error {
+	return s.db.AutoMigrate(&recordModel{})
+}
+
+// Begin claims key for a new request.
+func (s *PostgresStore) Begin(ctx context.Context, key, fingerprint string, lockedUntil time.Time) (*Record, string, error) {
+	rec, token, err := s.begin(ctx, key, fingerprint, lockedUntil)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// The record was deleted between the insert and the lookup, so the
+		// key is free again and the claim can be retried.
+		rec, token, err = s.begin(ctx, key, fingerprint, lockedUntil)
+	}
+	return rec, token, err
+}
+
+// begin makes one attempt to claim key. It returns an error wrapping
+// gorm.ErrRecordNotFound if the conflicting record disappeared meanwhile.
+func (s *PostgresStore) begin(ctx context.Context, key, fingerprint string, lockedUntil time.Time) (*Record, string, error) {
+	db := s.db.WithContext(ctx)
+	token := uuid.NewString()
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&recordModel{
+		Key:         key,
+		Fingerprint: fingerprint,
+		ClaimToken:  token,
+		LockedUntil: lockedUntil,
+		ExpiresAt:   lockedUntil,
+	})
+	if result.Error != nil {
+		return nil, "", fmt.Errorf("failed to claim idempotency key: %w", result.Error)
+	}
+	if result.RowsAffected == 1 {
+		return nil, token, nil
+	}
+
+	// Take over records that expired or whose in-flight owner went away.
+	now := time.Now().UTC()
+	result = db.Model(&recordModel{}).
+		Where("idempotency_key = ? AND ((completed AND expires_at <= ?) OR (NOT completed AND locked_until <= ?))", key, now, now).
+		Updates(map[string]interface{}{
+			"fingerprint":  fingerprint,
+			"claim_token":  token,
+			"completed":    false,
+			"status_code":  0,
+			"header":       "",
+			"body":         nil,
+			"locked_until": lockedUntil,
+			"expires_at":   lockedUntil,
+		})
+	if result.Error != nil {
+		return nil, "", fmt.Errorf("failed to claim idempotency key: %w", result.Error)
+	}
+	if result.RowsAffected == 1 {
+		return nil, token, nil
+	}
+
+	var model recordModel
+	if err := db.Where("idempotency_key = ?", key).First(&model).Error; err != nil {
+		return nil, "", fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+	rec, err := model.toRecord()
+	if err != nil {
+		return nil, "", err
+	}
+	return rec, "", nil
+}
+
+// Complete stores the response for key.
+func (s *PostgresStore) Complete(ctx context.Context, key, token string, resp Response, expiresAt time.Time) error {
+	header, err := json.Marshal(resp.Header)
+	if err != nil {
+		return fmt.Errorf("failed to encode response headers: %w", err)
+	}
+	result := s.db.WithContext(ctx).Model(&recordModel{}).
+		Where("idempotency_key = ? AND claim_token = ? AND NOT completed", key, token).
+		Updates(map[string]interface{}{
+			"completed":   true,
+			"status_code": resp.StatusCode,
+			"header":      string(header),
+			"body":        resp.Body,
+			"expires_at":  expiresAt,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to store idempotent response: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrClaimLost
+	}
+	return nil
+}
+
+// Release drops an in-flight claim.
+func (s *PostgresStore) Release(ctx context.Context, key, token string) error {
+	err := s.db.WithContext(ctx).
+		Where("idempotency_key = ? AND claim_token = ? AND NOT completed", key, token).
+		Delete(&recordModel{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpired removes records that can no longer be replayed.
+func (s *PostgresStore) DeleteExpired(ctx context.Context) (int64, error) {
+	result := s.db.WithContext(ctx).Where("expires_at < ?", time.Now().UTC()).Delete(&recordModel{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete expired idempotency keys: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
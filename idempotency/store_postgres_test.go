@@ -0,0 +1,76 @@
+package idempotency
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newMockPostgresStore returns a PostgresStore backed by sqlmock standing in
+// for a Postgres server.
+func newMockPostgresStore(t *testing.T) (*PostgresStore, sqlmock.Sqlmock) {
+	t.Helper()
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open: %v", err)
+	}
+	return NewPostgresStore(db), mock
+}
+
+func expectInsert(mock sqlmock.Sqlmock, rows int64) {
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "idempotency_keys"`)).
+		WillReturnResult(sqlmock.NewResult(0, rows))
+	mock.ExpectCommit()
+}
+
+func TestPostgresStoreBeginRetriesWhenRecordVanishes(t *testing.T) {
+	store, mock := newMockPostgresStore(t)
+
+	expectInsert(mock, 0)
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "idempotency_keys"`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "idempotency_keys" WHERE idempotency_key = $1`)).
+		WillReturnRows(sqlmock.NewRows([]string{"idempotency_key"}))
+	expectInsert(mock, 1)
+
+	rec, token, err := store.Begin(context.Background(), "key-1", "fp", time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	if rec != nil || token == "" {
+		t.Fatalf("Begin = %+v, %q, want a fresh claim", rec, token)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPostgresStoreBeginFreshClaim(t *testing.T) {
+	store, mock := newMockPostgresStore(t)
+	expectInsert(mock, 1)
+
+	rec, token, err := store.Begin(context.Background(), "key-1", "fp", time.Now().Add(time.Minute))
+	if err != nil || rec != nil || token == "" {
+		t.Fatalf("Begin = %+v, %q, %v, want a fresh claim", rec, token, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
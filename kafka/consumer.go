@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/Kilat-Pet-Delivery/lib-common/reqctx"
+	"github.com/Kilat-Pet-Delivery/lib-common/tracing"
 	"github.com/segmentio/kafka-go"
 	"go.uber.org/zap"
 )
@@ -17,6 +19,7 @@ type Consumer struct {
 	logger  *zap.Logger
 	topic   string
 	groupID string
+	tracer  *tracing.Tracer
 }
 
 // NewConsumer creates a new Kafka consumer.
@@ -37,8 +40,17 @@ func NewConsumer(brokers []string, groupID, topic string, logger *zap.Logger) *C
 	}
 }
 
+// WithTracer wraps every handler call in a consumer span that continues
+// the trace propagated in the message headers.
+func (c *Consumer) WithTracer(tracer *tracing.Tracer) *Consumer {
+	c.tracer = tracer
+	return c
+}
+
 // Consume starts consuming messages and delegates to the handler.
-// It blocks until the context is cancelled.
+// The handler's context carries the request ID, user and trace context from
+// the message headers and a logger annotated with them. It blocks until the context is
+// cancelled.
 func (c *Consumer) Consume(ctx context.Context, handler MessageHandler) error {
 	c.logger.Info("starting consumer",
 		zap.String("topic", c.topic),
@@ -63,12 +75,7 @@ func (c *Consumer) Consume(ctx context.Context, handler MessageHandler) error {
 				continue
 			}
 
-			if err := handler(ctx, msg); err != nil {
-				c.logger.Error("failed to handle message",
-					zap.String("topic", c.topic),
-					zap.Int64("offset", msg.Offset),
-					zap.Error(err),
-				)
+			if err := c.handle(ctx, msg, handler); err != nil {
 				continue
 			}
 
@@ -84,6 +91,27 @@ func (c *Consumer) Consume(ctx context.Context, handler MessageHandler) error {
 	}
 }
 
+// handle runs the handler for one message inside a consumer span.
+func (c *Consumer) handle(ctx context.Context, msg kafka.Message, handler MessageHandler) error {
+	if sc, ok := tracing.Extract(headerCarrier{headers: &msg.Headers}); ok {
+		ctx = tracing.ContextWithRemoteSpanContext(ctx, sc)
+	}
+	ctx, span := c.tracer.Start(ctx, "consume "+c.topic, tracing.SpanKindConsumer)
+	defer span.End()
+	span.SetAttribute("messaging.system", "kafka")
+	span.SetAttribute("messaging.destination", msg.Topic)
+	span.SetAttribute("messaging.kafka.partition", msg.Partition)
+	span.SetAttribute("messaging.kafka.offset", msg.Offset)
+
+	msgCtx := messageContext(ctx, msg, c.logger)
+	if err := handler(msgCtx, msg); err != nil {
+		span.RecordError(err)
+		reqctx.Logger(msgCtx, c.logger).Error("failed to handle message", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
 // Close closes the consumer.
 func (c *Consumer) Close() error {
 	if err := c.reader.Close(); err != nil {
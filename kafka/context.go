@@ -0,0 +1,92 @@
+package kafka
+
+import (
+	"context"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/reqctx"
+	"github.com/Kilat-Pet-Delivery/lib-common/tracing"
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+)
+
+// Message headers carrying request context between services.
+const (
+	HeaderRequestID = "request_id"
+	HeaderUserID    = "user_id"
+	HeaderUserRole  = "user_role"
+)
+
+// contextHeaders returns the request ID and user carried by ctx as message headers.
+func contextHeaders(ctx context.Context) []kafka.Header {
+	var headers []kafka.Header
+	if id := reqctx.RequestID(ctx); id != "" {
+		headers = append(headers, kafka.Header{Key: HeaderRequestID, Value: []byte(id)})
+	}
+	if userID, ok := reqctx.UserID(ctx); ok {
+		role, _ := reqctx.Role(ctx)
+		headers = append(headers,
+			kafka.Header{Key: HeaderUserID, Value: []byte(userID.String())},
+			kafka.Header{Key: HeaderUserRole, Value: []byte(role)},
+		)
+	}
+	return headers
+}
+
+// messageContext restores the request context carried by a message's
+// headers and attaches a logger annotated with it.
+func messageContext(ctx context.Context, msg kafka.Message, logger *zap.Logger) context.Context {
+	var userID, role string
+	for _, h := range msg.Headers {
+		switch h.Key {
+		case HeaderRequestID:
+			ctx = reqctx.WithRequestID(ctx, string(h.Value))
+		case HeaderUserID:
+			userID = string(h.Value)
+		case HeaderUserRole:
+			role = string(h.Value)
+		}
+	}
+	if id, err := uuid.Parse(userID); err == nil {
+		ctx = reqctx.WithUser(ctx, id, role)
+	}
+
+	fields := append(reqctx.Fields(ctx),
+		zap.String("topic", msg.Topic),
+		zap.Int("partition", msg.Partition),
+		zap.Int64("offset", msg.Offset),
+	)
+	if sc := tracing.SpanContextFromContext(ctx); sc.IsValid() {
+		fields = append(fields,
+			zap.String("trace_id", sc.TraceID.String()),
+			zap.String("span_id", sc.SpanID.String()),
+		)
+	}
+	return reqctx.WithLogger(ctx, logger.With(fields...))
+}
+
+// headerCarrier adapts message headers to tracing.Carrier.
+type headerCarrier struct {
+	headers *[]kafka.Header
+}
+
+// Get returns the first header value for key.
+func (h headerCarrier) Get(key string) string {
+	for _, header := range *h.headers {
+		if header.Key == key {
+			return string(header.Value)
+		}
+	}
+	return ""
+}
+
+// Set replaces any existing header for key.
+func (h headerCarrier) Set(key, value string) {
+	for i, header := range *h.headers {
+		if header.Key == key {
+			(*h.headers)[i].Value = []byte(value)
+			return
+		}
+	}
+	*h.headers = append(*h.headers, kafka.Header{Key: key, Value: []byte(value)})
+}
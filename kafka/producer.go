@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/Kilat-Pet-Delivery/lib-common/reqctx"
+	"github.com/Kilat-Pet-Delivery/lib-common/tracing"
 	"github.com/segmentio/kafka-go"
 	"go.uber.org/zap"
 )
@@ -15,6 +17,7 @@ type Producer struct {
 	writers map[string]*kafka.Writer
 	brokers []string
 	logger  *zap.Logger
+	tracer  *tracing.Tracer
 }
 
 // NewProducer creates a new Kafka producer.
@@ -26,6 +29,13 @@ func NewProducer(brokers []string, logger *zap.Logger) *Producer {
 	}
 }
 
+// WithTracer wraps every Publish in a producer span and propagates the
+// trace to consumers in the message headers.
+func (p *Producer) WithTracer(tracer *tracing.Tracer) *Producer {
+	p.tracer = tracer
+	return p
+}
+
 // getWriter returns or creates a writer for the given topic.
 func (p *Producer) getWriter(topic string) *kafka.Writer {
 	if w, exists := p.writers[topic]; exists {
@@ -42,8 +52,17 @@ func (p *Producer) getWriter(topic string) *kafka.Writer {
 	return w
 }
 
-// Publish sends a message to a Kafka topic.
-func (p *Producer) Publish(ctx context.Context, topic, key string, payload interface{}) error {
+// Publish sends a message to a Kafka topic. The request ID, user and trace
+// context carried by ctx are forwarded in the message headers.
+func (p *Producer) Publish(ctx context.Context, topic, key string, payload interface{}) (err error) {
+	ctx, span := p.tracer.Start(ctx, "publish "+topic, tracing.SpanKindProducer)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+	span.SetAttribute("messaging.system", "kafka")
+	span.SetAttribute("messaging.destination", topic)
+
 	data, err := json.Marshal(payload)
 	if err != nil {
 		return fmt.Errorf("failed to marshal payload: %w", err)
@@ -51,13 +70,16 @@ func (p *Producer) Publish(ctx context.Context, topic, key string, payload inter
 
 	writer := p.getWriter(topic)
 	msg := kafka.Message{
-		Key:   []byte(key),
-		Value: data,
-		Time:  time.Now().UTC(),
+		Key:     []byte(key),
+		Value:   data,
+		Headers: contextHeaders(ctx),
+		Time:    time.Now().UTC(),
 	}
+	tracing.Inject(ctx, headerCarrier{headers: &msg.Headers})
 
+	logger := reqctx.Logger(ctx, p.logger)
 	if err := writer.WriteMessages(ctx, msg); err != nil {
-		p.logger.Error("failed to publish message",
+		logger.Error("failed to publish message",
 			zap.String("topic", topic),
 			zap.String("key", key),
 			zap.Error(err),
@@ -65,7 +87,7 @@ func (p *Producer) Publish(ctx context.Context, topic, key string, payload inter
 		return fmt.Errorf("failed to publish to %s: %w", topic, err)
 	}
 
-	p.logger.Debug("message published",
+	logger.Debug("message published",
 		zap.String("topic", topic),
 		zap.String("key", key),
 	)
@@ -1,12 +1,17 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/Kilat-Pet-Delivery/lib-common/auth"
+	"github.com/Kilat-Pet-Delivery/lib-common/domain"
+	"github.com/Kilat-Pet-Delivery/lib-common/reqctx"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"go.uber.org/zap"
 )
 
 const (
@@ -16,10 +21,49 @@ const (
 	ContextKeyEmail = "email"
 	// ContextKeyRole is the gin context key for the authenticated user role.
 	ContextKeyRole = "role"
+	// ContextKeyServiceName is the gin context key for the calling service on service tokens.
+	ContextKeyServiceName = "service_name"
+	// ContextKeyClaims is the gin context key for the validated token claims.
+	ContextKeyClaims = "claims"
+	// ContextKeyActor is the gin context key for the admin behind an impersonation token.
+	ContextKeyActor = "actor"
 )
 
+// authConfig holds optional AuthMiddleware settings.
+type authConfig struct {
+	serviceAudiences []string
+	auditor          auth.Auditor
+	sessions         *auth.SessionManager
+}
+
+// AuthOption configures AuthMiddleware.
+type AuthOption func(*authConfig)
+
+// AllowServiceTokens makes AuthMiddleware also accept service tokens whose
+// aud claim contains one of the given audiences. Service tokens are rejected
+// unless this option is set.
+func AllowServiceTokens(audiences ...string) AuthOption {
+	return func(cfg *authConfig) {
+		cfg.serviceAudiences = append(cfg.serviceAudiences, audiences...)
+	}
+}
+
+// WithImpersonationAuditor makes AuthMiddleware accept impersonation tokens
+// and record every request made with one. Impersonation tokens are rejected
+// unless this option is set, so no impersonated request goes unaudited.
+func WithImpersonationAuditor(auditor auth.Auditor) AuthOption {
+	return func(cfg *authConfig) {
+		cfg.auditor = auditor
+	}
+}
+
 // AuthMiddleware creates a JWT authentication middleware.
-func AuthMiddleware(jwtManager *auth.JWTManager) gin.HandlerFunc {
+func AuthMiddleware(jwtManager *auth.JWTManager, opts ...AuthOption) gin.HandlerFunc {
+	var cfg authConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -37,8 +81,48 @@ func AuthMiddleware(jwtManager *auth.JWTManager) gin.HandlerFunc {
 			return
 		}
 
-		claims, err := jwtManager.ValidateAccessToken(parts[1])
+		claims, err := jwtManager.ValidateTokenContext(c.Request.Context(), parts[1])
 		if err != nil {
+			_ = c.Error(err)
+			if !auth.IsTokenError(err) {
+				c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+					"error": "authentication is temporarily unavailable",
+					"code":  "auth_unavailable",
+				})
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusUnauthorized, tokenErrorBody(err))
+			return
+		}
+
+		switch claims.TokenType {
+		case auth.AccessToken:
+			if claims.IsImpersonated() {
+				if cfg.auditor == nil {
+					c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+						"error": "impersonation tokens are not accepted",
+					})
+					return
+				}
+				c.Set(ContextKeyActor, claims.Actor)
+				auditImpersonation(c, cfg.auditor, claims)
+			}
+			if cfg.sessions != nil && !checkSession(c, cfg.sessions, claims) {
+				return
+			}
+		case auth.ServiceToken:
+			if !acceptsServiceToken(claims, cfg.serviceAudiences) {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+					"error": "service token not accepted for this audience",
+				})
+				return
+			}
+			c.Set(ContextKeyServiceName, claims.ServiceName)
+			c.Set(ContextKeyRole, claims.Role)
+			c.Set(ContextKeyClaims, claims)
+			c.Next()
+			return
+		default:
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
 				"error": "invalid or expired token",
 			})
@@ -48,10 +132,82 @@ func AuthMiddleware(jwtManager *auth.JWTManager) gin.HandlerFunc {
 		c.Set(ContextKeyUserID, claims.UserID)
 		c.Set(ContextKeyEmail, claims.Email)
 		c.Set(ContextKeyRole, claims.Role)
+		c.Set(ContextKeyClaims, claims)
+		setTenant(c, claims)
+		setRequestUser(c, claims.UserID, claims.Role)
 		c.Next()
 	}
 }
 
+// setRequestUser places the authenticated user on the request's context and
+// adds it to the request-scoped logger, if there is one.
+func setRequestUser(c *gin.Context, userID uuid.UUID, role auth.UserRole) {
+	ctx := reqctx.WithUser(c.Request.Context(), userID, string(role))
+	if logger, ok := reqctx.LoggerFrom(ctx); ok {
+		ctx = reqctx.WithLogger(ctx, logger.With(
+			zap.String("user_id", userID.String()),
+			zap.String("role", string(role)),
+		))
+	}
+	c.Request = c.Request.WithContext(ctx)
+}
+
+// tokenErrors maps typed token validation errors to a stable code and message.
+var tokenErrors = []struct {
+	err     error
+	code    string
+	message string
+}{
+	{auth.ErrTokenExpired, "token_expired", "token has expired"},
+	{auth.ErrTokenNotYetValid, "token_not_yet_valid", "token is not valid yet"},
+	{auth.ErrInvalidIssuer, "invalid_issuer", "token issuer is not accepted"},
+	{auth.ErrInvalidAudience, "invalid_audience", "token audience is not accepted"},
+	{auth.ErrInvalidSignature, "invalid_signature", "token signature is invalid"},
+	{auth.ErrUnknownKeyID, "invalid_signature", "token signing key is unknown"},
+	{auth.ErrTokenRevoked, "token_revoked", "token has been revoked"},
+	{auth.ErrTokenMalformed, "token_malformed", "token is malformed"},
+}
+
+// tokenErrorBody builds the 401 response body for a token validation error.
+func tokenErrorBody(err error) gin.H {
+	for _, te := range tokenErrors {
+		if errors.Is(err, te.err) {
+			return gin.H{"error": te.message, "code": te.code}
+		}
+	}
+	return gin.H{"error": "invalid or expired token", "code": "invalid_token"}
+}
+
+// auditImpersonation records a request made by an admin acting as another user.
+func auditImpersonation(c *gin.Context, auditor auth.Auditor, claims *auth.Claims) {
+	auditor.Audit(c.Request.Context(), auth.AuditEvent{
+		Type:       auth.AuditImpersonation,
+		ActorID:    claims.Actor.UserID,
+		ActorRole:  claims.Actor.Role,
+		Resource:   "user",
+		ResourceID: claims.UserID.String(),
+		Action:     c.Request.Method + " " + c.FullPath(),
+		Allowed:    true,
+		Metadata: map[string]interface{}{
+			"jti":       claims.ID,
+			"path":      c.Request.URL.Path,
+			"client_ip": c.ClientIP(),
+			"user_role": string(claims.Role),
+		},
+		OccurredAt: time.Now().UTC(),
+	})
+}
+
+// acceptsServiceToken reports whether the token's audience is one the route accepts.
+func acceptsServiceToken(claims *auth.Claims, audiences []string) bool {
+	for _, aud := range audiences {
+		if claims.HasAudience(aud) {
+			return true
+		}
+	}
+	return false
+}
+
 // RequireRole creates middleware that restricts access to specific roles.
 func RequireRole(roles ...auth.UserRole) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -103,3 +259,84 @@ func GetUserRole(c *gin.Context) (auth.UserRole, bool) {
 	role, ok := val.(auth.UserRole)
 	return role, ok
 }
+
+// RequirePermission creates middleware that requires the authenticated role
+// to hold every listed permission under the given policy.
+func RequirePermission(policy *auth.Policy, perms ...auth.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userRole, ok := GetUserRole(c)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "authentication required",
+			})
+			return
+		}
+
+		if err := policy.Authorize(userRole, perms...); err != nil {
+			abortWithError(c, err)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// GetClaims extracts the validated token claims from the gin context.
+func GetClaims(c *gin.Context) (*auth.Claims, bool) {
+	val, exists := c.Get(ContextKeyClaims)
+	if !exists {
+		return nil, false
+	}
+	claims, ok := val.(*auth.Claims)
+	return claims, ok
+}
+
+// GetActor returns the admin behind an impersonation token. It returns false
+// for ordinary requests.
+func GetActor(c *gin.Context) (*auth.Actor, bool) {
+	val, exists := c.Get(ContextKeyActor)
+	if !exists {
+		return nil, false
+	}
+	actor, ok := val.(*auth.Actor)
+	return actor, ok
+}
+
+// GetServiceName extracts the calling service name for requests made with a service token.
+func GetServiceName(c *gin.Context) (string, bool) {
+	val, exists := c.Get(ContextKeyServiceName)
+	if !exists {
+		return "", false
+	}
+	name, ok := val.(string)
+	return name, ok
+}
+
+// GetPrincipal returns the authenticated caller for authorization checks.
+func GetPrincipal(c *gin.Context) (auth.Principal, bool) {
+	userID, ok := GetUserID(c)
+	if !ok {
+		return auth.Principal{}, false
+	}
+	role, ok := GetUserRole(c)
+	if !ok {
+		return auth.Principal{}, false
+	}
+	return auth.Principal{UserID: userID, Role: role}, true
+}
+
+// abortWithError aborts the request with the status and message of a DomainError.
+func abortWithError(c *gin.Context, err error) {
+	var domainErr *domain.DomainError
+	if !errors.As(err, &domainErr) {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": "internal server error",
+		})
+		return
+	}
+	_ = c.Error(err)
+	c.AbortWithStatusJSON(domainErr.Code, gin.H{
+		"error":  domainErr.Message,
+		"detail": domainErr.Detail,
+	})
+}
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// failingRevocationStore is a RevocationStore whose backend is unreachable.
+type failingRevocationStore struct {
+	auth.RevocationStore
+}
+
+func (failingRevocationStore) IsRevoked(context.Context, *auth.Claims) (bool, error) {
+	return false, errors.New("connection refused")
+}
+
+func serveAuth(t *testing.T, m *auth.JWTManager, token string) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/me", AuthMiddleware(m), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestAuthMiddlewareStatus(t *testing.T) {
+	revocations := auth.NewMemoryRevocationStore()
+	tests := []struct {
+		name   string
+		store  auth.RevocationStore
+		revoke bool
+		token  string
+		want   int
+	}{
+		{name: "valid", store: revocations, want: http.StatusOK},
+		{name: "revoked", store: revocations, revoke: true, want: http.StatusUnauthorized},
+		{name: "malformed", store: revocations, token: "not-a-jwt", want: http.StatusUnauthorized},
+		{name: "store unavailable", store: failingRevocationStore{}, want: http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := auth.NewJWTManager("test-secret", time.Minute, time.Hour, auth.WithRevocationStore(tt.store))
+			token := tt.token
+			if token == "" {
+				var err error
+				token, err = m.GenerateAccessToken(uuid.New(), "owner@example.com", auth.RoleOwner)
+				if err != nil {
+					t.Fatalf("GenerateAccessToken: %v", err)
+				}
+			}
+			if tt.revoke {
+				claims, err := m.ValidateToken(token)
+				if err != nil {
+					t.Fatalf("ValidateToken: %v", err)
+				}
+				if err := m.RevokeToken(context.Background(), claims); err != nil {
+					t.Fatalf("RevokeToken: %v", err)
+				}
+			}
+
+			if w := serveAuth(t, m, token); w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+		})
+	}
+}
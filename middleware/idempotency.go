@@ -0,0 +1,161 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/idempotency"
+	"github.com/Kilat-Pet-Delivery/lib-common/reqctx"
+	"github.com/Kilat-Pet-Delivery/lib-common/tracing"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// perRequestHeaders describe a single request rather than its result, so
+// they are not stored for replay.
+var perRequestHeaders = []string{
+	reqctx.HeaderRequestID,
+	HeaderRateLimitLimit,
+	HeaderRateLimitRemaining,
+	HeaderRateLimitReset,
+	HeaderRetryAfter,
+	tracing.HeaderTraceparent,
+	tracing.HeaderTracestate,
+	idempotency.HeaderReplayed,
+	"Date",
+}
+
+// idempotencyWriter captures the response body so it can be stored for replay.
+type idempotencyWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *idempotencyWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *idempotencyWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// IdempotencyMiddleware makes requests carrying an Idempotency-Key header
+// safe to retry. The first request's response is stored and replayed for
+// retries with the same key and body; reusing a key with a different body
+// or while the first request is still running returns 409. Keys are scoped
+// to the authenticated user when there is one, so run it after
+// AuthMiddleware. Server errors are not stored so the client can retry them.
+// Unset cfg fields take their DefaultConfig values.
+func IdempotencyMiddleware(store idempotency.Store, cfg idempotency.Config, logger *zap.Logger) gin.HandlerFunc {
+	cfg = cfg.WithDefaults()
+
+	return func(c *gin.Context) {
+		key := c.GetHeader(idempotency.HeaderKey)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, cfg.MaxBodyBytes))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{
+				"error": "request body is too large",
+			})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		scope := "anonymous"
+		if userID, ok := GetUserID(c); ok {
+			scope = userID.String()
+		}
+		storeKey := scope + ":" + c.Request.Method + ":" + c.Request.URL.Path + ":" + key
+		fingerprint := idempotency.Fingerprint(c.Request.Method, c.Request.URL.Path, body)
+
+		rec, token, err := store.Begin(c.Request.Context(), storeKey, fingerprint, time.Now().Add(cfg.LockTimeout))
+		if err != nil {
+			_ = c.Error(err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"error": "internal server error",
+			})
+			return
+		}
+
+		if token == "" {
+			replayIdempotent(c, rec, fingerprint)
+			return
+		}
+
+		// The claim must be settled even if the client has gone away, or a
+		// retry would run the handler again once the lock lapses.
+		ctx := context.WithoutCancel(c.Request.Context())
+		completed := false
+		defer func() {
+			if !completed {
+				if err := store.Release(ctx, storeKey, token); err != nil {
+					reqctx.Logger(ctx, logger).Error("failed to release idempotency key", zap.Error(err))
+				}
+			}
+		}()
+
+		writer := &idempotencyWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+
+		if writer.Status() >= http.StatusInternalServerError {
+			return
+		}
+		header := writer.Header().Clone()
+		for _, name := range perRequestHeaders {
+			header.Del(name)
+		}
+		resp := idempotency.Response{
+			StatusCode: writer.Status(),
+			Header:     header,
+			Body:       writer.body.Bytes(),
+		}
+		if err := store.Complete(ctx, storeKey, token, resp, time.Now().Add(cfg.TTL)); err != nil {
+			if errors.Is(err, idempotency.ErrClaimLost) {
+				completed = true
+			}
+			reqctx.Logger(ctx, logger).Error("failed to store idempotent response", zap.Error(err))
+			return
+		}
+		completed = true
+	}
+}
+
+// replayIdempotent answers a request whose key is already known.
+func replayIdempotent(c *gin.Context, rec *idempotency.Record, fingerprint string) {
+	if rec.Fingerprint != fingerprint {
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{
+			"error": "idempotency key was already used for a different request",
+		})
+		return
+	}
+	if !rec.Completed {
+		c.Header(HeaderRetryAfter, "1")
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{
+			"error": "a request with this idempotency key is still in progress",
+		})
+		return
+	}
+
+	header := c.Writer.Header()
+	for name, values := range rec.Header {
+		header.Del(name)
+		for _, v := range values {
+			header.Add(name, v)
+		}
+	}
+	c.Header(idempotency.HeaderReplayed, "true")
+	c.Status(rec.StatusCode)
+	_, _ = c.Writer.Write(rec.Body)
+	c.Abort()
+}
@@ -3,21 +3,41 @@ package middleware
 import (
 	"time"
 
+	"github.com/Kilat-Pet-Delivery/lib-common/reqctx"
+	"github.com/Kilat-Pet-Delivery/lib-common/tracing"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
-// LoggerMiddleware creates a Zap-based request logging middleware.
+// LoggerMiddleware creates a Zap-based request logging middleware. It also
+// places a request-scoped logger on the request's context.Context;
+// RequestIDMiddleware adds the request ID to it, TracingMiddleware the trace
+// ID and AuthMiddleware the user, whichever order they are registered in.
 func LoggerMiddleware(logger *zap.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
 		path := c.Request.URL.Path
 		query := c.Request.URL.RawQuery
 
+		reqLogger := logger
+		if requestID := reqctx.RequestID(c.Request.Context()); requestID != "" {
+			reqLogger = reqLogger.With(zap.String("request_id", requestID))
+		}
+		if sc := tracing.SpanContextFromContext(c.Request.Context()); sc.IsValid() {
+			reqLogger = reqLogger.With(traceFields(sc)...)
+		}
+		c.Request = c.Request.WithContext(reqctx.WithLogger(c.Request.Context(), reqLogger))
+
 		c.Next()
 
 		latency := time.Since(start)
 		status := c.Writer.Status()
+		reqLogger = reqctx.Logger(c.Request.Context(), reqLogger)
+		if reqctx.RequestID(c.Request.Context()) == "" {
+			if requestID := c.GetHeader(reqctx.HeaderRequestID); requestID != "" {
+				reqLogger = reqLogger.With(zap.String("request_id", requestID))
+			}
+		}
 
 		fields := []zap.Field{
 			zap.Int("status", status),
@@ -29,18 +49,19 @@ func LoggerMiddleware(logger *zap.Logger) gin.HandlerFunc {
 			zap.Int("body_size", c.Writer.Size()),
 		}
 
-		if requestID := c.GetHeader("X-Request-ID"); requestID != "" {
-			fields = append(fields, zap.String("request_id", requestID))
-		}
-
 		if len(c.Errors) > 0 {
-			logger.Error("request error", append(fields, zap.String("errors", c.Errors.String()))...)
+			reqLogger.Error("request error", append(fields, zap.String("errors", c.Errors.String()))...)
 		} else if status >= 500 {
-			logger.Error("server error", fields...)
+			reqLogger.Error("server error", fields...)
 		} else if status >= 400 {
-			logger.Warn("client error", fields...)
+			reqLogger.Warn("client error", fields...)
 		} else {
-			logger.Info("request", fields...)
+			reqLogger.Info("request", fields...)
 		}
 	}
 }
+
+// GetLogger returns the request-scoped logger, or fallback if LoggerMiddleware did not run.
+func GetLogger(c *gin.Context, fallback *zap.Logger) *zap.Logger {
+	return reqctx.Logger(c.Request.Context(), fallback)
+}
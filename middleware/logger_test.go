@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/resilience"
+	"github.com/Kilat-Pet-Delivery/lib-common/tracing"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestRetryLogsCarryRequestContext(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tracer := tracing.NewTracer("booking-service", tracing.NewMemoryExporter())
+
+	orders := map[string]func(logger *zap.Logger) []gin.HandlerFunc{
+		"logger first": func(logger *zap.Logger) []gin.HandlerFunc {
+			return []gin.HandlerFunc{LoggerMiddleware(logger), RequestIDMiddleware(), TracingMiddleware(tracer)}
+		},
+		"logger last": func(logger *zap.Logger) []gin.HandlerFunc {
+			return []gin.HandlerFunc{TracingMiddleware(tracer), RequestIDMiddleware(), LoggerMiddleware(logger)}
+		},
+	}
+	for name, chain := range orders {
+		t.Run(name, func(t *testing.T) {
+			core, logs := observer.New(zapcore.InfoLevel)
+			r := gin.New()
+			r.Use(chain(zap.New(core))...)
+			r.GET("/", func(c *gin.Context) {
+				attempts := 0
+				retry := resilience.RetryConfig{MaxRetries: 1, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+				_ = resilience.WithRetry(c.Request.Context(), retry, zap.NewNop(), "charge", func() error {
+					attempts++
+					if attempts == 1 {
+						return errors.New("timeout")
+					}
+					return nil
+				})
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("X-Request-ID", "req-1")
+			r.ServeHTTP(httptest.NewRecorder(), req)
+
+			retries := logs.FilterMessage("operation failed, retrying").All()
+			if len(retries) != 1 {
+				t.Fatalf("logged %d retries, want 1", len(retries))
+			}
+			fields := retries[0].ContextMap()
+			if fields["request_id"] != "req-1" {
+				t.Fatalf("request_id = %v, want req-1", fields["request_id"])
+			}
+			if id, _ := fields["trace_id"].(string); len(id) != 32 {
+				t.Fatalf("trace_id = %v, want a trace ID", fields["trace_id"])
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireMFA creates middleware for sensitive routes that requires the
+// access token to record completed two-factor authentication. When maxAge is
+// positive, the second factor must also have been completed within maxAge.
+func RequireMFA(maxAge time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := GetClaims(c)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "authentication required",
+			})
+			return
+		}
+
+		if !claims.MFAVerified() {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error": "two-factor authentication required",
+				"code":  "mfa_required",
+			})
+			return
+		}
+
+		if maxAge > 0 && time.Since(claims.MFAAt.Time) > maxAge {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error": "two-factor authentication has expired",
+				"code":  "mfa_expired",
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
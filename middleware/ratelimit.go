@@ -1,66 +1,206 @@
 package middleware
 
 import (
+	"errors"
+	"fmt"
+	"math"
 	"net/http"
-	"sync"
+	"strconv"
 	"time"
 
+	"github.com/Kilat-Pet-Delivery/lib-common/auth"
+	"github.com/Kilat-Pet-Delivery/lib-common/ratelimit"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
-type visitor struct {
-	count    int
-	lastSeen time.Time
+// Rate limit response headers.
+const (
+	HeaderRateLimitLimit     = "RateLimit-Limit"
+	HeaderRateLimitRemaining = "RateLimit-Remaining"
+	HeaderRateLimitReset     = "RateLimit-Reset"
+	HeaderRetryAfter         = "Retry-After"
+)
+
+// RateLimitKeyFunc derives the key a request is counted under.
+type RateLimitKeyFunc func(c *gin.Context) string
+
+// KeyByIP counts requests per client IP.
+func KeyByIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// KeyByUser counts requests per authenticated user, falling back to the
+// client IP for anonymous requests.
+func KeyByUser(c *gin.Context) string {
+	if userID, ok := GetUserID(c); ok {
+		return "user:" + userID.String()
+	}
+	return KeyByIP(c)
+}
+
+// KeyByRole counts requests per role, so every user with a role shares one
+// budget. Anonymous requests fall back to the client IP.
+func KeyByRole(c *gin.Context) string {
+	if role, ok := GetUserRole(c); ok {
+		return "role:" + string(role)
+	}
+	return KeyByIP(c)
+}
+
+// KeyByAPIKey counts requests per API key, falling back to the client IP
+// for requests not made with one.
+func KeyByAPIKey(c *gin.Context) string {
+	if val, ok := c.Get(ContextKeyAPIKeyID); ok {
+		if id, ok := val.(uuid.UUID); ok {
+			return "apikey:" + id.String()
+		}
+	}
+	return KeyByIP(c)
 }
 
-// RateLimitMiddleware limits requests per IP.
+// RateLimitPolicy is one limit applied to a route group. Name separates the
+// counters of different groups, so a strict "login" policy does not share a
+// budget with the rest of the API.
+type RateLimitPolicy struct {
+	Name  string
+	Key   RateLimitKeyFunc
+	Limit ratelimit.Limit
+	// RoleLimits overrides Limit for authenticated users with these roles.
+	RoleLimits map[auth.UserRole]ratelimit.Limit
+}
+
+// limitFor returns the limit that applies to the request.
+func (p RateLimitPolicy) limitFor(c *gin.Context) ratelimit.Limit {
+	if role, ok := GetUserRole(c); ok {
+		if limit, ok := p.RoleLimits[role]; ok {
+			return limit
+		}
+	}
+	return p.Limit
+}
+
+// validate checks the policy's default and per-role limits.
+func (p RateLimitPolicy) validate() error {
+	if err := p.Limit.Validate(); err != nil {
+		return fmt.Errorf("rate limit policy %q: %w", p.Name, err)
+	}
+	for role, limit := range p.RoleLimits {
+		if err := limit.Validate(); err != nil {
+			return fmt.Errorf("rate limit policy %q for role %q: %w", p.Name, role, err)
+		}
+	}
+	return nil
+}
+
+// RateLimitFailureMode decides what happens to a request when the limiter's
+// store cannot be reached.
+type RateLimitFailureMode int
+
+const (
+	// FailClosed rejects the request with 503, so limits protecting logins
+	// or other abuse targets are never skipped.
+	FailClosed RateLimitFailureMode = iota
+	// FailOpen lets the request through unlimited, so an outage of the store
+	// does not take the API down with it.
+	FailOpen
+)
+
+// RateLimitMiddleware limits requests per IP within a single process using
+// a token bucket, which keeps constant-size state per client. Limits
+// multiply with the replica count; use RateLimiterMiddleware with a shared
+// store when that matters. A non-positive maxRequests or window rejects
+// every request with 500, since there is no error to return.
 func RateLimitMiddleware(maxRequests int, window time.Duration) gin.HandlerFunc {
-	var mu sync.Mutex
-	visitors := make(map[string]*visitor)
-
-	// Cleanup goroutine
-	go func() {
-		for {
-			time.Sleep(window)
-			mu.Lock()
-			for ip, v := range visitors {
-				if time.Since(v.lastSeen) > window {
-					delete(visitors, ip)
-				}
-			}
-			mu.Unlock()
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.TokenBucket{})
+	handler, err := RateLimiterMiddleware(limiter, ratelimit.Limit{Rate: maxRequests, Period: window}, FailClosed)
+	if err != nil {
+		_ = limiter.Close()
+		return func(c *gin.Context) {
+			_ = c.Error(err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"error": "internal server error",
+			})
 		}
-	}()
+	}
+	return handler
+}
 
-	return func(c *gin.Context) {
-		ip := c.ClientIP()
-		mu.Lock()
-		v, exists := visitors[ip]
-		if !exists {
-			visitors[ip] = &visitor{count: 1, lastSeen: time.Now()}
-			mu.Unlock()
-			c.Next()
-			return
+// RateLimiterMiddleware limits requests per IP using the given limiter.
+func RateLimiterMiddleware(limiter *ratelimit.Limiter, limit ratelimit.Limit, mode RateLimitFailureMode) (gin.HandlerFunc, error) {
+	return RateLimitPolicies(limiter, mode, RateLimitPolicy{Name: "default", Key: KeyByIP, Limit: limit})
+}
+
+// RateLimitPolicies applies every policy to the request and rejects it with
+// 429 if any is exceeded. The RateLimit-* headers describe the most
+// restrictive policy. Policies keyed by user or role must run after
+// AuthMiddleware. mode decides whether a request is rejected or let through
+// when the limiter's store fails. It returns an error if any policy has an
+// invalid limit.
+func RateLimitPolicies(limiter *ratelimit.Limiter, mode RateLimitFailureMode, policies ...RateLimitPolicy) (gin.HandlerFunc, error) {
+	if len(policies) == 0 {
+		return nil, errors.New("no rate limit policies given")
+	}
+	for _, p := range policies {
+		if err := p.validate(); err != nil {
+			return nil, err
 		}
+	}
 
-		if time.Since(v.lastSeen) > window {
-			v.count = 1
-			v.lastSeen = time.Now()
-			mu.Unlock()
+	return func(c *gin.Context) {
+		var tightest *ratelimit.Result
+		for _, p := range policies {
+			keyFunc := p.Key
+			if keyFunc == nil {
+				keyFunc = KeyByIP
+			}
+
+			result, err := limiter.Allow(c.Request.Context(), p.Name+":"+keyFunc(c), p.limitFor(c))
+			if err != nil {
+				_ = c.Error(err)
+				if mode == FailOpen {
+					continue
+				}
+				c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+					"error": "rate limiter is unavailable",
+				})
+				return
+			}
+			if tightest == nil || !result.Allowed || result.Remaining < tightest.Remaining {
+				r := result
+				tightest = &r
+			}
+			if !result.Allowed {
+				break
+			}
+		}
+		if tightest == nil {
 			c.Next()
 			return
 		}
 
-		v.count++
-		v.lastSeen = time.Now()
-		if v.count > maxRequests {
-			mu.Unlock()
+		setRateLimitHeaders(c, tightest)
+		if !tightest.Allowed {
 			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
 				"error": "rate limit exceeded",
 			})
 			return
 		}
-		mu.Unlock()
 		c.Next()
+	}, nil
+}
+
+// setRateLimitHeaders writes the RateLimit-* and Retry-After headers.
+func setRateLimitHeaders(c *gin.Context, result *ratelimit.Result) {
+	c.Header(HeaderRateLimitLimit, strconv.Itoa(result.Limit))
+	c.Header(HeaderRateLimitRemaining, strconv.Itoa(result.Remaining))
+	c.Header(HeaderRateLimitReset, strconv.Itoa(ceilSeconds(result.ResetAfter)))
+	if !result.Allowed {
+		c.Header(HeaderRetryAfter, strconv.Itoa(ceilSeconds(result.RetryAfter)))
 	}
 }
+
+// ceilSeconds rounds a duration up to whole seconds.
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
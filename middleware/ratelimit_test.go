@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/auth"
+	"github.com/Kilat-Pet-Delivery/lib-common/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+// failingStore is a ratelimit.Store whose backend is unreachable.
+type failingStore struct{}
+
+func (failingStore) Update(context.Context, string, func([]byte) ([]byte, time.Duration, error)) error {
+	return errors.New("connection refused")
+}
+
+func (failingStore) Close() error { return nil }
+
+func serveRateLimited(t *testing.T, handler gin.HandlerFunc, n int) []int {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/", handler, func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	codes := make([]int, n)
+	for i := range codes {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		codes[i] = w.Code
+	}
+	return codes
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	codes := serveRateLimited(t, RateLimitMiddleware(2, time.Minute), 3)
+	want := []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests}
+	for i := range want {
+		if codes[i] != want[i] {
+			t.Fatalf("request %d: status = %d, want %d", i+1, codes[i], want[i])
+		}
+	}
+}
+
+func TestRateLimitMiddlewareInvalidLimit(t *testing.T) {
+	if code := serveRateLimited(t, RateLimitMiddleware(0, time.Minute), 1)[0]; code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want %d", code, http.StatusInternalServerError)
+	}
+}
+
+func TestRateLimitPoliciesRejectsInvalidLimits(t *testing.T) {
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.TokenBucket{})
+	defer limiter.Close()
+
+	tests := []struct {
+		name     string
+		policies []RateLimitPolicy
+	}{
+		{"none", nil},
+		{"zero rate", []RateLimitPolicy{{Name: "api", Limit: ratelimit.PerMinute(0)}}},
+		{"zero period", []RateLimitPolicy{{Name: "api", Limit: ratelimit.Limit{Rate: 1}}}},
+		{"role limit", []RateLimitPolicy{{
+			Name:       "api",
+			Limit:      ratelimit.PerMinute(10),
+			RoleLimits: map[auth.UserRole]ratelimit.Limit{auth.RoleAdmin: ratelimit.PerMinute(-1)},
+		}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := RateLimitPolicies(limiter, FailClosed, tt.policies...); err == nil {
+				t.Fatal("RateLimitPolicies accepted an invalid policy")
+			}
+		})
+	}
+}
+
+func TestRateLimitPoliciesFailureMode(t *testing.T) {
+	limiter := ratelimit.NewLimiter(failingStore{}, ratelimit.TokenBucket{})
+	tests := []struct {
+		mode RateLimitFailureMode
+		want int
+	}{
+		{FailOpen, http.StatusOK},
+		{FailClosed, http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		handler, err := RateLimiterMiddleware(limiter, ratelimit.PerMinute(1), tt.mode)
+		if err != nil {
+			t.Fatalf("RateLimiterMiddleware: %v", err)
+		}
+		if code := serveRateLimited(t, handler, 1)[0]; code != tt.want {
+			t.Fatalf("mode %d: status = %d, want %d", tt.mode, code, tt.want)
+		}
+	}
+}
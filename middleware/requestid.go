@@ -1,19 +1,43 @@
 package middleware
 
 import (
+	"github.com/Kilat-Pet-Delivery/lib-common/reqctx"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"go.uber.org/zap"
 )
 
-// RequestIDMiddleware adds a unique request ID to each request.
+// ContextKeyRequestID is the gin context key for the request ID.
+const ContextKeyRequestID = "request_id"
+
+// RequestIDMiddleware adds a unique request ID to each request. The ID is
+// also placed on the request's context.Context for code outside gin, and
+// added to the request-scoped logger if LoggerMiddleware already ran.
 func RequestIDMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		requestID := c.GetHeader("X-Request-ID")
+		requestID := c.GetHeader(reqctx.HeaderRequestID)
 		if requestID == "" {
 			requestID = uuid.New().String()
 		}
-		c.Set("request_id", requestID)
-		c.Header("X-Request-ID", requestID)
+		c.Set(ContextKeyRequestID, requestID)
+		c.Header(reqctx.HeaderRequestID, requestID)
+
+		ctx := c.Request.Context()
+		if reqctx.RequestID(ctx) == "" {
+			if logger, ok := reqctx.LoggerFrom(ctx); ok {
+				ctx = reqctx.WithLogger(ctx, logger.With(zap.String("request_id", requestID)))
+			}
+		}
+		c.Request = c.Request.WithContext(reqctx.WithRequestID(ctx, requestID))
 		c.Next()
 	}
 }
+
+// GetRequestID returns the request ID set by RequestIDMiddleware, falling
+// back to the X-Request-ID header.
+func GetRequestID(c *gin.Context) string {
+	if id := reqctx.RequestID(c.Request.Context()); id != "" {
+		return id
+	}
+	return c.GetHeader(reqctx.HeaderRequestID)
+}
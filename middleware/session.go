@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	// ContextKeySessionID is the gin context key for the current session ID.
+	ContextKeySessionID = "session_id"
+	// HeaderClientPlatform is the request header clients use to report their platform.
+	HeaderClientPlatform = "X-Client-Platform"
+)
+
+// WithSessions makes AuthMiddleware reject access tokens whose session has
+// been revoked and record device activity on each request. Access tokens
+// without a family_id claim are not bound to a session and are let through.
+func WithSessions(sessions *auth.SessionManager) AuthOption {
+	return func(cfg *authConfig) {
+		cfg.sessions = sessions
+	}
+}
+
+// DeviceFromRequest builds session device metadata from the request.
+func DeviceFromRequest(c *gin.Context) auth.DeviceInfo {
+	return auth.DeviceInfo{
+		UserAgent: c.Request.UserAgent(),
+		IP:        c.ClientIP(),
+		Platform:  c.GetHeader(HeaderClientPlatform),
+	}
+}
+
+// GetSessionID extracts the current session ID from the gin context.
+func GetSessionID(c *gin.Context) (uuid.UUID, bool) {
+	val, exists := c.Get(ContextKeySessionID)
+	if !exists {
+		return uuid.UUID{}, false
+	}
+	id, ok := val.(uuid.UUID)
+	return id, ok
+}
+
+// checkSession validates the session behind an access token and aborts the
+// request if it was revoked. It returns false if the request was aborted.
+func checkSession(c *gin.Context, sessions *auth.SessionManager, claims *auth.Claims) bool {
+	if claims.FamilyID == "" {
+		return true
+	}
+	sessionID, err := uuid.Parse(claims.FamilyID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+			"error": "invalid or expired token",
+			"code":  "invalid_token",
+		})
+		return false
+	}
+
+	_, err = sessions.Validate(c.Request.Context(), sessionID, claims.UserID, DeviceFromRequest(c))
+	switch {
+	case err == nil:
+		c.Set(ContextKeySessionID, sessionID)
+		return true
+	case errors.Is(err, auth.ErrSessionRevoked), errors.Is(err, auth.ErrSessionNotFound), errors.Is(err, auth.ErrSessionExpired):
+		_ = c.Error(err)
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+			"error": "session has been signed out",
+			"code":  "session_revoked",
+		})
+	default:
+		_ = c.Error(err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": "internal server error",
+		})
+	}
+	return false
+}
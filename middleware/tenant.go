@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	// ContextKeyTenantID is the gin context key for the tenant the token is scoped to.
+	ContextKeyTenantID = "tenant_id"
+	// ContextKeyTenantRole is the gin context key for the user's role in that tenant.
+	ContextKeyTenantRole = "tenant_role"
+)
+
+// setTenant copies the token's tenant scope into the gin context.
+func setTenant(c *gin.Context, claims *auth.Claims) {
+	if claims.Tenant == nil {
+		return
+	}
+	c.Set(ContextKeyTenantID, claims.Tenant.ID)
+	c.Set(ContextKeyTenantRole, claims.Tenant.Role)
+}
+
+// RequireTenantRole creates middleware that requires the token to be scoped
+// to a tenant in which the user holds one of the given roles.
+func RequireTenantRole(roles ...auth.TenantRole) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenantRole, ok := GetTenantRole(c)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error": "tenant membership required",
+			})
+			return
+		}
+
+		for _, role := range roles {
+			if tenantRole == role {
+				c.Next()
+				return
+			}
+		}
+
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"error": "insufficient tenant permissions",
+		})
+	}
+}
+
+// GetTenantID extracts the current tenant ID from the gin context.
+func GetTenantID(c *gin.Context) (uuid.UUID, bool) {
+	val, exists := c.Get(ContextKeyTenantID)
+	if !exists {
+		return uuid.UUID{}, false
+	}
+	id, ok := val.(uuid.UUID)
+	return id, ok
+}
+
+// GetTenantRole extracts the user's role in the current tenant from the gin context.
+func GetTenantRole(c *gin.Context) (auth.TenantRole, bool) {
+	val, exists := c.Get(ContextKeyTenantRole)
+	if !exists {
+		return "", false
+	}
+	role, ok := val.(auth.TenantRole)
+	return role, ok
+}
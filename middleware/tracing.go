@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/reqctx"
+	"github.com/Kilat-Pet-Delivery/lib-common/tracing"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// TracingMiddleware continues the caller's W3C trace, or starts a new one,
+// and wraps the rest of the chain in a server span. The span's traceparent
+// is returned in the response so clients can quote it in bug reports. The
+// trace ID is added to the request-scoped logger whichever order this and
+// LoggerMiddleware are registered in.
+func TracingMiddleware(tracer *tracing.Tracer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		if sc, ok := tracing.Extract(tracing.HeaderCarrier(c.Request.Header)); ok {
+			ctx = tracing.ContextWithRemoteSpanContext(ctx, sc)
+		}
+
+		ctx, span := tracer.Start(ctx, c.Request.Method+" "+c.Request.URL.Path, tracing.SpanKindServer)
+		defer span.End()
+		if span == nil {
+			c.Next()
+			return
+		}
+
+		sc := span.SpanContext()
+		if logger, ok := reqctx.LoggerFrom(ctx); ok {
+			ctx = reqctx.WithLogger(ctx, logger.With(traceFields(sc)...))
+		}
+		c.Request = c.Request.WithContext(ctx)
+		c.Header(tracing.HeaderTraceparent, sc.Traceparent())
+
+		c.Next()
+
+		if route := c.FullPath(); route != "" {
+			span.SetName(c.Request.Method + " " + route)
+			span.SetAttribute("http.route", route)
+		}
+		status := c.Writer.Status()
+		span.SetAttribute("http.method", c.Request.Method)
+		span.SetAttribute("http.target", c.Request.URL.Path)
+		span.SetAttribute("http.status_code", status)
+		if status >= http.StatusInternalServerError {
+			span.RecordError(fmt.Errorf("HTTP %d", status))
+		}
+		if len(c.Errors) > 0 {
+			span.RecordError(c.Errors.Last())
+		}
+	}
+}
+
+// traceFields returns the trace and span IDs as log fields.
+func traceFields(sc tracing.SpanContext) []zap.Field {
+	return []zap.Field{
+		zap.String("trace_id", sc.TraceID.String()),
+		zap.String("span_id", sc.SpanID.String()),
+	}
+}
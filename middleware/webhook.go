@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/webhook"
+	"github.com/gin-gonic/gin"
+)
+
+// ContextKeyWebhookID is the gin context key for the verified webhook message ID.
+const ContextKeyWebhookID = "webhook_id"
+
+// maxWebhookBodyBytes caps how much of a webhook body is read for verification.
+const maxWebhookBodyBytes = 1 << 20
+
+// WebhookSignatureMiddleware verifies the signature of incoming webhooks
+// against the raw request body. The body is restored afterwards so handlers
+// can bind it as usual.
+func WebhookSignatureMiddleware(verifier *webhook.Verifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxWebhookBodyBytes))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{
+				"error": "webhook body is too large",
+			})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		if err := verifier.Verify(c.Request.Header, body); err != nil {
+			_ = c.Error(err)
+			message := "invalid webhook signature"
+			if errors.Is(err, webhook.ErrTimestampOutOfRange) {
+				message = "webhook timestamp is outside the tolerance window"
+			}
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": message,
+			})
+			return
+		}
+
+		c.Set(ContextKeyWebhookID, c.GetHeader(webhook.HeaderID))
+		c.Next()
+	}
+}
@@ -0,0 +1,93 @@
+package ratelimit
+
+import (
+	"encoding/json"
+	"math"
+	"time"
+)
+
+// TokenBucket refills Rate tokens per Period up to Burst and spends one per
+// request, allowing short bursts while holding the average rate.
+type TokenBucket struct{}
+
+type tokenBucketState struct {
+	Tokens  float64 `json:"t"`
+	Updated int64   `json:"u"`
+}
+
+// Allow implements Algorithm.
+func (TokenBucket) Allow(state []byte, limit Limit, now time.Time) ([]byte, time.Duration, Result, error) {
+	capacity := float64(limit.capacity())
+	perNano := float64(limit.Rate) / float64(limit.Period)
+
+	s := tokenBucketState{Tokens: capacity, Updated: now.UnixNano()}
+	if state != nil {
+		if err := json.Unmarshal(state, &s); err != nil {
+			return nil, 0, Result{}, err
+		}
+		elapsed := float64(now.UnixNano() - s.Updated)
+		if elapsed > 0 {
+			s.Tokens = math.Min(capacity, s.Tokens+elapsed*perNano)
+		}
+		s.Updated = now.UnixNano()
+	}
+
+	result := Result{Limit: limit.capacity()}
+	if s.Tokens >= 1 {
+		s.Tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = time.Duration(math.Ceil((1 - s.Tokens) / perNano))
+	}
+	result.Remaining = int(math.Floor(s.Tokens))
+	result.ResetAfter = time.Duration(math.Ceil((capacity - s.Tokens) / perNano))
+
+	next, err := json.Marshal(s)
+	if err != nil {
+		return nil, 0, Result{}, err
+	}
+	return next, result.ResetAfter + time.Second, result, nil
+}
+
+// SlidingWindowLog records the time of every request and allows at most Rate
+// within any Period. It is exact at the cost of storing one entry per request.
+type SlidingWindowLog struct{}
+
+// Allow implements Algorithm.
+func (SlidingWindowLog) Allow(state []byte, limit Limit, now time.Time) ([]byte, time.Duration, Result, error) {
+	var hits []int64
+	if state != nil {
+		if err := json.Unmarshal(state, &hits); err != nil {
+			return nil, 0, Result{}, err
+		}
+	}
+
+	cutoff := now.Add(-limit.Period).UnixNano()
+	live := hits[:0]
+	for _, h := range hits {
+		if h > cutoff {
+			live = append(live, h)
+		}
+	}
+
+	result := Result{Limit: limit.Rate}
+	if len(live) < limit.Rate {
+		live = append(live, now.UnixNano())
+		result.Allowed = true
+	} else {
+		result.RetryAfter = time.Duration(live[len(live)-limit.Rate] - cutoff)
+	}
+	result.Remaining = limit.Rate - len(live)
+	if result.Remaining < 0 {
+		result.Remaining = 0
+	}
+	if len(live) > 0 {
+		result.ResetAfter = time.Duration(live[len(live)-1] - cutoff)
+	}
+
+	next, err := json.Marshal(live)
+	if err != nil {
+		return nil, 0, Result{}, err
+	}
+	return next, limit.Period, result, nil
+}
@@ -0,0 +1,107 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidLimit is returned for limits with a non-positive rate or period.
+var ErrInvalidLimit = errors.New("invalid rate limit")
+
+// Limit allows Rate requests per Period. Burst is the token bucket capacity
+// and defaults to Rate; the sliding window log ignores it.
+type Limit struct {
+	Rate   int
+	Period time.Duration
+	Burst  int
+}
+
+// PerSecond returns a limit of n requests per second.
+func PerSecond(n int) Limit { return Limit{Rate: n, Period: time.Second} }
+
+// PerMinute returns a limit of n requests per minute.
+func PerMinute(n int) Limit { return Limit{Rate: n, Period: time.Minute} }
+
+// PerHour returns a limit of n requests per hour.
+func PerHour(n int) Limit { return Limit{Rate: n, Period: time.Hour} }
+
+// capacity returns the most requests that may be made at once.
+func (l Limit) capacity() int {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+	return l.Rate
+}
+
+// Validate rejects limits that cannot admit any request.
+func (l Limit) Validate() error {
+	if l.Rate <= 0 || l.Period <= 0 {
+		return fmt.Errorf("%w: %d per %s", ErrInvalidLimit, l.Rate, l.Period)
+	}
+	return nil
+}
+
+// Result describes the outcome of a rate limit check.
+type Result struct {
+	Allowed bool
+	// Limit is the number of requests allowed at once.
+	Limit int
+	// Remaining is how many more requests are allowed right now.
+	Remaining int
+	// RetryAfter is how long to wait before the next request is allowed.
+	// It is zero when the request was allowed.
+	RetryAfter time.Duration
+	// ResetAfter is how long until the full limit is available again.
+	ResetAfter time.Duration
+}
+
+// Algorithm decides whether a request is allowed given the stored state
+// for its key. It returns the state to store, which lives for ttl.
+type Algorithm interface {
+	Allow(state []byte, limit Limit, now time.Time) (next []byte, ttl time.Duration, result Result, err error)
+}
+
+// Store holds algorithm state shared by every replica.
+type Store interface {
+	// Update atomically loads the state for key, applies fn and saves the
+	// returned state for ttl. fn receives nil when the key has no live state.
+	Update(ctx context.Context, key string, fn func(state []byte) (next []byte, ttl time.Duration, err error)) error
+	// Close releases the store's resources and stops any background work.
+	Close() error
+}
+
+// Limiter checks requests against limits using an algorithm and a store.
+type Limiter struct {
+	store     Store
+	algorithm Algorithm
+}
+
+// NewLimiter creates a limiter.
+func NewLimiter(store Store, algorithm Algorithm) *Limiter {
+	return &Limiter{store: store, algorithm: algorithm}
+}
+
+// Allow records a request for key and reports whether it is within limit.
+func (l *Limiter) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	if err := limit.Validate(); err != nil {
+		return Result{}, err
+	}
+
+	var result Result
+	err := l.store.Update(ctx, key, func(state []byte) ([]byte, time.Duration, error) {
+		next, ttl, r, err := l.algorithm.Allow(state, limit, time.Now())
+		result = r
+		return next, ttl, err
+	})
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to check rate limit: %w", err)
+	}
+	return result, nil
+}
+
+// Close closes the underlying store.
+func (l *Limiter) Close() error {
+	return l.store.Close()
+}
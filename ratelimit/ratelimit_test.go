@@ -0,0 +1,58 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestLimiterAlgorithms(t *testing.T) {
+	tests := []struct {
+		name string
+		alg  Algorithm
+	}{
+		{"token bucket", TokenBucket{}},
+		{"sliding window log", SlidingWindowLog{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := NewLimiter(NewMemoryStore(), tt.alg)
+			defer limiter.Close()
+
+			ctx := context.Background()
+			for i := 0; i < 3; i++ {
+				res, err := limiter.Allow(ctx, "ip:1", PerMinute(3))
+				if err != nil {
+					t.Fatalf("Allow: %v", err)
+				}
+				if !res.Allowed || res.Remaining != 2-i {
+					t.Fatalf("request %d: got allowed=%v remaining=%d", i+1, res.Allowed, res.Remaining)
+				}
+			}
+
+			res, err := limiter.Allow(ctx, "ip:1", PerMinute(3))
+			if err != nil {
+				t.Fatalf("Allow: %v", err)
+			}
+			if res.Allowed || res.RetryAfter <= 0 {
+				t.Fatalf("over limit: got allowed=%v retryAfter=%v", res.Allowed, res.RetryAfter)
+			}
+
+			res, err = limiter.Allow(ctx, "ip:2", PerMinute(3))
+			if err != nil || !res.Allowed {
+				t.Fatalf("other key: got allowed=%v err=%v", res.Allowed, err)
+			}
+		})
+	}
+}
+
+func TestMemoryStoreClosed(t *testing.T) {
+	store := NewMemoryStore()
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	_, err := NewLimiter(store, TokenBucket{}).Allow(context.Background(), "ip:1", PerSecond(1))
+	if !errors.Is(err, ErrStoreClosed) {
+		t.Fatalf("Allow after Close: got %v, want ErrStoreClosed", err)
+	}
+}
@@ -0,0 +1,78 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrStoreClosed is returned by a store after Close.
+var ErrStoreClosed = errors.New("rate limit store is closed")
+
+// memorySweepInterval is how often Update also drops expired keys.
+const memorySweepInterval = time.Minute
+
+type memoryEntry struct {
+	state     []byte
+	expiresAt time.Time
+}
+
+// MemoryStore is an in-process Store for tests and single-instance
+// deployments. Expired keys are swept during updates, so it runs no
+// background goroutine.
+type MemoryStore struct {
+	mu        sync.Mutex
+	entries   map[string]memoryEntry
+	lastSweep time.Time
+	closed    bool
+}
+
+// NewMemoryStore creates an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]memoryEntry), lastSweep: time.Now()}
+}
+
+// Update atomically applies fn to the state for key.
+func (s *MemoryStore) Update(_ context.Context, key string, fn func(state []byte) ([]byte, time.Duration, error)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrStoreClosed
+	}
+
+	now := time.Now()
+	if now.Sub(s.lastSweep) >= memorySweepInterval {
+		s.sweep(now)
+	}
+
+	var state []byte
+	if e, ok := s.entries[key]; ok && now.Before(e.expiresAt) {
+		state = e.state
+	}
+	next, ttl, err := fn(state)
+	if err != nil {
+		return err
+	}
+	s.entries[key] = memoryEntry{state: next, expiresAt: now.Add(ttl)}
+	return nil
+}
+
+// Close drops all state. Later updates return ErrStoreClosed.
+func (s *MemoryStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	s.entries = nil
+	return nil
+}
+
+// sweep removes expired keys. The caller must hold s.mu.
+func (s *MemoryStore) sweep(now time.Time) {
+	for key, e := range s.entries {
+		if !now.Before(e.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+	s.lastSweep = now
+}
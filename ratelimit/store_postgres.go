@@ -0,0 +1,137 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// rateLimitModel is the GORM model for the rate_limits table.
+type rateLimitModel struct {
+	Key       string    `gorm:"column:bucket_key;primaryKey"`
+	State     []byte    `gorm:"type:bytea"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+}
+
+// TableName sets the table name for GORM.
+func (rateLimitModel) TableName() string { return "rate_limits" }
+
+// PostgresStore is a GORM-backed Store shared by every replica. Each update
+// locks the key's row for the duration of a short transaction.
+type PostgresStore struct {
+	db     *gorm.DB
+	logger *zap.Logger
+
+	mu       sync.Mutex
+	started  bool
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewPostgresStore creates a Postgres rate limit store.
+func NewPostgresStore(db *gorm.DB, logger *zap.Logger) *PostgresStore {
+	return &PostgresStore{
+		db:     db,
+		logger: logger,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+// AutoMigrate creates or updates the rate_limits table.
+func (s *PostgresStore) AutoMigrate() error {
+	return s.db.AutoMigrate(&rateLimitModel{})
+}
+
+// Update atomically applies fn to the state for key.
+func (s *PostgresStore) Update(ctx context.Context, key string, fn func(state []byte) ([]byte, time.Duration, error)) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now().UTC()
+		// Make sure the row exists so it can be locked even on first use.
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&rateLimitModel{Key: key, ExpiresAt: now}).Error; err != nil {
+			return fmt.Errorf("failed to create rate limit row: %w", err)
+		}
+
+		var model rateLimitModel
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("bucket_key = ?", key).First(&model).Error; err != nil {
+			return fmt.Errorf("failed to lock rate limit row: %w", err)
+		}
+
+		var state []byte
+		if now.Before(model.ExpiresAt) {
+			state = model.State
+		}
+		next, ttl, err := fn(state)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&rateLimitModel{}).Where("bucket_key = ?", key).Updates(map[string]interface{}{
+			"state":      next,
+			"expires_at": now.Add(ttl),
+		}).Error
+		if err != nil {
+			return fmt.Errorf("failed to save rate limit state: %w", err)
+		}
+		return nil
+	})
+}
+
+// DeleteExpired removes keys whose state has expired.
+func (s *PostgresStore) DeleteExpired(ctx context.Context) (int64, error) {
+	result := s.db.WithContext(ctx).Where("expires_at < ?", time.Now().UTC()).Delete(&rateLimitModel{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete expired rate limits: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// Start deletes expired keys every interval until ctx is cancelled or Close is called.
+func (s *PostgresStore) Start(ctx context.Context, interval time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		return
+	}
+	s.started = true
+
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-s.stop:
+				return
+			case <-ticker.C:
+				if _, err := s.DeleteExpired(ctx); err != nil {
+					s.logger.Warn("failed to delete expired rate limits", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// Close stops the cleanup loop started by Start and waits for it to exit.
+func (s *PostgresStore) Close() error {
+	s.stopOnce.Do(func() { close(s.stop) })
+
+	s.mu.Lock()
+	started := s.started
+	s.mu.Unlock()
+	if started {
+		<-s.done
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package ratelimit
+
+import (
+	"context"
+	"database/sql/driver"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newMockPostgresStore returns a PostgresStore backed by sqlmock standing in
+// for a Postgres server.
+func newMockPostgresStore(t *testing.T) (*PostgresStore, sqlmock.Sqlmock) {
+	t.Helper()
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open: %v", err)
+	}
+	return NewPostgresStore(db, zap.NewNop()), mock
+}
+
+// anyTime matches any time.Time argument.
+type anyTime struct{}
+
+func (anyTime) Match(v driver.Value) bool {
+	_, ok := v.(time.Time)
+	return ok
+}
+
+func expectUpdate(mock sqlmock.Sqlmock, key string, state []byte, expiresAt time.Time) {
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "rate_limits"`)).
+		WithArgs(key, sqlmock.AnyArg(), anyTime{}).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "rate_limits" WHERE bucket_key = $1 ORDER BY "rate_limits"."bucket_key" LIMIT $2 FOR UPDATE`)).
+		WithArgs(key, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"bucket_key", "state", "expires_at"}).AddRow(key, state, expiresAt))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "rate_limits" SET "expires_at"=$1,"state"=$2 WHERE bucket_key = $3`)).
+		WithArgs(anyTime{}, sqlmock.AnyArg(), key).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+}
+
+func TestPostgresStoreUpdateLocksRow(t *testing.T) {
+	store, mock := newMockPostgresStore(t)
+	limiter := NewLimiter(store, TokenBucket{})
+
+	expectUpdate(mock, "user:1", nil, time.Now().Add(-time.Second))
+	res, err := limiter.Allow(context.Background(), "user:1", PerMinute(2))
+	if err != nil {
+		t.Fatalf("Allow: %v", err)
+	}
+	if !res.Allowed || res.Remaining != 1 {
+		t.Fatalf("first request: got allowed=%v remaining=%d, want allowed with 1 remaining", res.Allowed, res.Remaining)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPostgresStoreUsesStoredState(t *testing.T) {
+	store, mock := newMockPostgresStore(t)
+	now := time.Now()
+	state, _, _, err := TokenBucket{}.Allow(nil, PerMinute(1), now)
+	if err != nil {
+		t.Fatalf("TokenBucket.Allow: %v", err)
+	}
+
+	expectUpdate(mock, "user:1", state, now.Add(time.Minute))
+	res, err := NewLimiter(store, TokenBucket{}).Allow(context.Background(), "user:1", PerMinute(1))
+	if err != nil {
+		t.Fatalf("Allow: %v", err)
+	}
+	if res.Allowed {
+		t.Fatal("request over the limit was allowed")
+	}
+	if res.RetryAfter <= 0 {
+		t.Fatalf("RetryAfter = %v, want > 0", res.RetryAfter)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPostgresStoreCloseStopsCleanup(t *testing.T) {
+	store, mock := newMockPostgresStore(t)
+	mock.MatchExpectationsInOrder(false)
+	for i := 0; i < 100; i++ {
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "rate_limits" WHERE expires_at < $1`)).
+			WithArgs(anyTime{}).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+
+	store.Start(context.Background(), time.Millisecond)
+	store.Start(context.Background(), time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+
+	done := make(chan struct{})
+	go func() {
+		_ = store.Close()
+		_ = store.Close()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Close did not stop the cleanup loop")
+	}
+}
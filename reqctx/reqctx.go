@@ -0,0 +1,117 @@
+package reqctx
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// HeaderRequestID is the HTTP header carrying the request ID.
+const HeaderRequestID = "X-Request-ID"
+
+type ctxKey int
+
+const (
+	requestIDKey ctxKey = iota
+	userKey
+	loggerKey
+)
+
+type user struct {
+	id   uuid.UUID
+	role string
+}
+
+// WithRequestID returns a copy of ctx carrying the request ID.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestID returns the request ID carried by ctx, or "" if there is none.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// WithUser returns a copy of ctx carrying the authenticated user and role.
+func WithUser(ctx context.Context, userID uuid.UUID, role string) context.Context {
+	return context.WithValue(ctx, userKey, user{id: userID, role: role})
+}
+
+// UserID returns the authenticated user carried by ctx.
+func UserID(ctx context.Context) (uuid.UUID, bool) {
+	u, ok := ctx.Value(userKey).(user)
+	return u.id, ok
+}
+
+// Role returns the authenticated user's role carried by ctx.
+func Role(ctx context.Context) (string, bool) {
+	u, ok := ctx.Value(userKey).(user)
+	return u.role, ok
+}
+
+// WithLogger returns a copy of ctx carrying a request-scoped logger.
+func WithLogger(ctx context.Context, logger *zap.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey, logger)
+}
+
+// LoggerFrom returns the request-scoped logger carried by ctx, if any.
+func LoggerFrom(ctx context.Context) (*zap.Logger, bool) {
+	logger, ok := ctx.Value(loggerKey).(*zap.Logger)
+	return logger, ok
+}
+
+// Logger returns the request-scoped logger carried by ctx. Without one it
+// returns fallback annotated with the request ID and user from ctx, or a
+// no-op logger if fallback is nil.
+func Logger(ctx context.Context, fallback *zap.Logger) *zap.Logger {
+	if logger, ok := LoggerFrom(ctx); ok {
+		return logger
+	}
+	if fallback == nil {
+		return zap.NewNop()
+	}
+	if fields := Fields(ctx); len(fields) > 0 {
+		return fallback.With(fields...)
+	}
+	return fallback
+}
+
+// Fields returns the request ID and user carried by ctx as log fields.
+func Fields(ctx context.Context) []zap.Field {
+	var fields []zap.Field
+	if id := RequestID(ctx); id != "" {
+		fields = append(fields, zap.String("request_id", id))
+	}
+	if u, ok := ctx.Value(userKey).(user); ok {
+		fields = append(fields, zap.String("user_id", u.id.String()), zap.String("role", u.role))
+	}
+	return fields
+}
+
+// Transport is an http.RoundTripper that forwards the request ID carried by
+// the outgoing request's context.
+type Transport struct {
+	Base http.RoundTripper
+}
+
+// NewTransport wraps base, or http.DefaultTransport if base is nil.
+func NewTransport(base http.RoundTripper) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Transport{Base: base}
+}
+
+// RoundTrip adds the X-Request-ID header and delegates to the base transport.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	id := RequestID(req.Context())
+	if id == "" || req.Header.Get(HeaderRequestID) != "" {
+		return t.Base.RoundTrip(req)
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set(HeaderRequestID, id)
+	return t.Base.RoundTrip(req)
+}
@@ -5,6 +5,7 @@ import (
 	"math"
 	"time"
 
+	"github.com/Kilat-Pet-Delivery/lib-common/reqctx"
 	"go.uber.org/zap"
 )
 
@@ -25,8 +26,10 @@ func DefaultRetryConfig() RetryConfig {
 }
 
 // WithRetry executes a function with exponential backoff retries.
+// Retries are logged with the request-scoped logger carried by ctx, if any.
 func WithRetry(ctx context.Context, config RetryConfig, logger *zap.Logger, operation string, fn func() error) error {
 	var lastErr error
+	logger = reqctx.Logger(ctx, logger)
 
 	for attempt := 0; attempt <= config.MaxRetries; attempt++ {
 		if err := fn(); err != nil {
@@ -0,0 +1,83 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// MemoryExporter keeps finished spans in memory so tracing can be inspected
+// in tests without a collector.
+type MemoryExporter struct {
+	mu    sync.Mutex
+	spans []SpanData
+}
+
+// NewMemoryExporter creates an empty in-memory exporter.
+func NewMemoryExporter() *MemoryExporter {
+	return &MemoryExporter{}
+}
+
+// Export stores the span.
+func (e *MemoryExporter) Export(_ context.Context, span SpanData) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, span)
+	return nil
+}
+
+// Spans returns the spans exported so far, in the order they ended.
+func (e *MemoryExporter) Spans() []SpanData {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]SpanData(nil), e.spans...)
+}
+
+// Reset drops every stored span.
+func (e *MemoryExporter) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = nil
+}
+
+// Shutdown does nothing.
+func (e *MemoryExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+// ZapExporter writes finished spans to a Zap logger at debug level.
+type ZapExporter struct {
+	logger *zap.Logger
+}
+
+// NewZapExporter creates an exporter that logs to the given logger.
+func NewZapExporter(logger *zap.Logger) *ZapExporter {
+	return &ZapExporter{logger: logger.Named("tracing")}
+}
+
+// Export logs the span.
+func (e *ZapExporter) Export(_ context.Context, span SpanData) error {
+	fields := []zap.Field{
+		zap.String("service", span.Service),
+		zap.String("kind", string(span.Kind)),
+		zap.String("trace_id", span.TraceID),
+		zap.String("span_id", span.SpanID),
+		zap.String("parent_span_id", span.ParentSpanID),
+		zap.Duration("duration", span.Duration()),
+	}
+	if len(span.Attributes) > 0 {
+		fields = append(fields, zap.Any("attributes", span.Attributes))
+	}
+	if span.Error != "" {
+		fields = append(fields, zap.String("error", span.Error))
+	}
+	e.logger.Debug(span.Name, fields...)
+	return nil
+}
+
+// Shutdown flushes the logger.
+func (e *ZapExporter) Shutdown(context.Context) error {
+	_ = e.logger.Sync()
+	return nil
+}
@@ -0,0 +1,145 @@
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// W3C Trace Context headers.
+const (
+	HeaderTraceparent = "traceparent"
+	HeaderTracestate  = "tracestate"
+)
+
+// ErrInvalidTraceparent is returned for traceparent values that do not follow W3C Trace Context.
+var ErrInvalidTraceparent = errors.New("invalid traceparent")
+
+// flagSampled is the trace-flags bit recording that the caller sampled the trace.
+const flagSampled byte = 0x01
+
+// TraceID identifies a trace.
+type TraceID [16]byte
+
+// IsValid returns false for the all-zero trace ID.
+func (t TraceID) IsValid() bool { return t != TraceID{} }
+
+// String returns the lowercase hex form.
+func (t TraceID) String() string { return hex.EncodeToString(t[:]) }
+
+// SpanID identifies a span within a trace.
+type SpanID [8]byte
+
+// IsValid returns false for the all-zero span ID.
+func (s SpanID) IsValid() bool { return s != SpanID{} }
+
+// String returns the lowercase hex form.
+func (s SpanID) String() string { return hex.EncodeToString(s[:]) }
+
+// SpanContext is the part of a span that crosses process boundaries.
+type SpanContext struct {
+	TraceID    TraceID
+	SpanID     SpanID
+	Flags      byte
+	TraceState string
+	// Remote is true when the context was extracted from an incoming request or message.
+	Remote bool
+}
+
+// IsValid returns true if both IDs are set.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// IsSampled returns true if the sampled flag is set.
+func (sc SpanContext) IsSampled() bool {
+	return sc.Flags&flagSampled != 0
+}
+
+// Traceparent returns the W3C traceparent header value.
+func (sc SpanContext) Traceparent() string {
+	return fmt.Sprintf("00-%s-%s-%02x", sc.TraceID, sc.SpanID, sc.Flags)
+}
+
+// ParseTraceparent parses a W3C traceparent header value. Versions above 00
+// are accepted as long as they start with the version 00 fields.
+func ParseTraceparent(value string) (SpanContext, error) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+	version, traceID, spanID, flags := parts[0], parts[1], parts[2], parts[3]
+	if len(version) != 2 || version == "ff" || !isLowerHex(version) {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+	if version == "00" && len(parts) != 4 {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+	if len(traceID) != 32 || len(spanID) != 16 || len(flags) != 2 ||
+		!isLowerHex(traceID) || !isLowerHex(spanID) || !isLowerHex(flags) {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+
+	var sc SpanContext
+	hex.Decode(sc.TraceID[:], []byte(traceID))
+	hex.Decode(sc.SpanID[:], []byte(spanID))
+	var f [1]byte
+	hex.Decode(f[:], []byte(flags))
+	sc.Flags = f[0]
+	if !sc.IsValid() {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+	return sc, nil
+}
+
+// isLowerHex reports whether s contains only 0-9 and a-f.
+func isLowerHex(s string) bool {
+	for _, r := range s {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// Carrier reads and writes trace context headers on a transport.
+type Carrier interface {
+	Get(key string) string
+	Set(key, value string)
+}
+
+// HeaderCarrier adapts http.Header to Carrier.
+type HeaderCarrier http.Header
+
+// Get returns the header value.
+func (h HeaderCarrier) Get(key string) string { return http.Header(h).Get(key) }
+
+// Set sets the header value.
+func (h HeaderCarrier) Set(key, value string) { http.Header(h).Set(key, value) }
+
+// Inject writes the span context carried by ctx into the carrier.
+func Inject(ctx context.Context, carrier Carrier) {
+	sc := SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return
+	}
+	carrier.Set(HeaderTraceparent, sc.Traceparent())
+	if sc.TraceState != "" {
+		carrier.Set(HeaderTracestate, sc.TraceState)
+	}
+}
+
+// Extract reads a remote span context from the carrier. It returns false if
+// there is no valid traceparent.
+func Extract(carrier Carrier) (SpanContext, bool) {
+	sc, err := ParseTraceparent(carrier.Get(HeaderTraceparent))
+	if err != nil {
+		return SpanContext{}, false
+	}
+	sc.TraceState = carrier.Get(HeaderTracestate)
+	sc.Remote = true
+	return sc, true
+}
@@ -0,0 +1,90 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+		sampled bool
+	}{
+		{"sampled", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", false, true},
+		{"not sampled", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", false, false},
+		{"future version with extra field", "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", false, true},
+		{"empty", "", true, false},
+		{"version ff", "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", true, false},
+		{"version 00 with extra field", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", true, false},
+		{"upper case", "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", true, false},
+		{"short trace id", "00-4bf92f3577b34da6-00f067aa0ba902b7-01", true, false},
+		{"zero trace id", "00-00000000000000000000000000000000-00f067aa0ba902b7-01", true, false},
+		{"zero span id", "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc, err := ParseTraceparent(tt.value)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidTraceparent) {
+					t.Fatalf("got %v, want ErrInvalidTraceparent", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseTraceparent: %v", err)
+			}
+			if sc.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" || sc.SpanID.String() != "00f067aa0ba902b7" {
+				t.Fatalf("got trace %s span %s", sc.TraceID, sc.SpanID)
+			}
+			if sc.IsSampled() != tt.sampled {
+				t.Fatalf("IsSampled = %v, want %v", sc.IsSampled(), tt.sampled)
+			}
+		})
+	}
+}
+
+func TestInjectExtract(t *testing.T) {
+	tracer := NewTracer("booking-service", NewMemoryExporter())
+	remote := SpanContext{
+		TraceID:    TraceID{1},
+		SpanID:     SpanID{2},
+		Flags:      flagSampled,
+		TraceState: "vendor=1",
+	}
+	ctx, span := tracer.Start(ContextWithRemoteSpanContext(context.Background(), remote), "publish", SpanKindProducer)
+
+	header := http.Header{}
+	Inject(ctx, HeaderCarrier(header))
+	if got, want := header.Get(HeaderTraceparent), span.SpanContext().Traceparent(); got != want {
+		t.Fatalf("traceparent = %q, want %q", got, want)
+	}
+
+	sc, ok := Extract(HeaderCarrier(header))
+	if !ok {
+		t.Fatal("Extract found no trace context")
+	}
+	if !sc.Remote {
+		t.Fatal("extracted context is not marked remote")
+	}
+	if sc.TraceID != remote.TraceID || sc.SpanID != span.SpanContext().SpanID {
+		t.Fatalf("extracted trace %s span %s", sc.TraceID, sc.SpanID)
+	}
+	if sc.TraceState != "vendor=1" {
+		t.Fatalf("tracestate = %q, want vendor=1", sc.TraceState)
+	}
+}
+
+func TestInjectWithoutSpan(t *testing.T) {
+	header := http.Header{}
+	Inject(context.Background(), HeaderCarrier(header))
+	if len(header) != 0 {
+		t.Fatalf("Inject wrote headers without a span: %v", header)
+	}
+	if _, ok := Extract(HeaderCarrier(header)); ok {
+		t.Fatal("Extract found a trace context in empty headers")
+	}
+}
@@ -0,0 +1,112 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestTracerParentChild(t *testing.T) {
+	exporter := NewMemoryExporter()
+	tracer := NewTracer("booking-service", exporter)
+
+	ctx, parent := tracer.Start(context.Background(), "create booking", SpanKindServer)
+	_, child := tracer.Start(ctx, "insert booking", SpanKindInternal)
+	child.RecordError(errors.New("duplicate key"))
+	child.End()
+	parent.End()
+
+	spans := exporter.Spans()
+	if len(spans) != 2 {
+		t.Fatalf("exported %d spans, want 2", len(spans))
+	}
+	c, p := spans[0], spans[1]
+	if p.ParentSpanID != "" {
+		t.Fatalf("root span has parent %q", p.ParentSpanID)
+	}
+	if c.TraceID != p.TraceID {
+		t.Fatalf("child trace %s, want %s", c.TraceID, p.TraceID)
+	}
+	if c.ParentSpanID != p.SpanID {
+		t.Fatalf("child parent %s, want %s", c.ParentSpanID, p.SpanID)
+	}
+	if c.Service != "booking-service" || c.Kind != SpanKindInternal {
+		t.Fatalf("child service/kind = %s/%s", c.Service, c.Kind)
+	}
+	if c.Error != "duplicate key" {
+		t.Fatalf("child error = %q, want %q", c.Error, "duplicate key")
+	}
+}
+
+func TestTracerContinuesRemoteTrace(t *testing.T) {
+	remote, err := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if err != nil {
+		t.Fatalf("ParseTraceparent: %v", err)
+	}
+	exporter := NewMemoryExporter()
+	tracer := NewTracer("payment-service", exporter)
+
+	_, span := tracer.Start(ContextWithRemoteSpanContext(context.Background(), remote), "charge", SpanKindServer)
+	span.End()
+
+	got := exporter.Spans()[0]
+	if got.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || got.ParentSpanID != "00f067aa0ba902b7" {
+		t.Fatalf("span trace/parent = %s/%s, want the remote trace", got.TraceID, got.ParentSpanID)
+	}
+}
+
+func TestTracerSkipsUnsampledTrace(t *testing.T) {
+	remote, err := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	if err != nil {
+		t.Fatalf("ParseTraceparent: %v", err)
+	}
+	exporter := NewMemoryExporter()
+	tracer := NewTracer("payment-service", exporter)
+
+	_, span := tracer.Start(ContextWithRemoteSpanContext(context.Background(), remote), "charge", SpanKindServer)
+	span.End()
+
+	if n := len(exporter.Spans()); n != 0 {
+		t.Fatalf("exported %d unsampled spans, want 0", n)
+	}
+}
+
+func TestSpanEndCopiesAttributes(t *testing.T) {
+	exporter := NewMemoryExporter()
+	tracer := NewTracer("booking-service", exporter)
+
+	_, span := tracer.Start(context.Background(), "create booking", SpanKindServer)
+	span.SetAttribute("booking.id", "b-1")
+	span.End()
+	span.SetAttribute("booking.id", "b-2")
+	span.SetName("renamed")
+	span.End()
+
+	spans := exporter.Spans()
+	if len(spans) != 1 {
+		t.Fatalf("exported %d spans, want 1", len(spans))
+	}
+	if got := spans[0].Attributes["booking.id"]; got != "b-1" {
+		t.Fatalf("exported attribute = %v, want b-1", got)
+	}
+	if spans[0].Name != "create booking" {
+		t.Fatalf("exported name = %q, want %q", spans[0].Name, "create booking")
+	}
+}
+
+func TestNilTracerAndSpan(t *testing.T) {
+	var tracer *Tracer
+	ctx, span := tracer.Start(context.Background(), "noop", SpanKindInternal)
+	if span != nil {
+		t.Fatal("nil tracer created a span")
+	}
+	span.SetAttribute("k", "v")
+	span.RecordError(errors.New("ignored"))
+	span.End()
+	if SpanFromContext(ctx) != nil {
+		t.Fatal("nil tracer stored a span in the context")
+	}
+	if err := tracer.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+}
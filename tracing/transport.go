@@ -0,0 +1,47 @@
+package tracing
+
+import (
+	"net/http"
+	"net/url"
+)
+
+// Transport is an http.RoundTripper that creates a client span for each
+// outgoing request and sends the traceparent header.
+type Transport struct {
+	Base   http.RoundTripper
+	Tracer *Tracer
+}
+
+// NewTransport wraps base, or http.DefaultTransport if base is nil.
+func NewTransport(tracer *Tracer, base http.RoundTripper) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Transport{Base: base, Tracer: tracer}
+}
+
+// RoundTrip traces the request and delegates to the base transport.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, span := t.Tracer.Start(req.Context(), "HTTP "+req.Method, SpanKindClient)
+	defer span.End()
+	span.SetAttribute("http.method", req.Method)
+	span.SetAttribute("http.url", redactURL(req.URL))
+
+	req = req.Clone(ctx)
+	Inject(ctx, HeaderCarrier(req.Header))
+
+	resp, err := t.Base.RoundTrip(req)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	span.SetAttribute("http.status_code", resp.StatusCode)
+	return resp, nil
+}
+
+// redactURL drops the query, fragment and user info, which often carry
+// credentials such as signed URL signatures or tokens.
+func redactURL(u *url.URL) string {
+	clean := url.URL{Scheme: u.Scheme, Host: u.Host, Path: u.Path, RawPath: u.RawPath}
+	return clean.String()
+}
@@ -0,0 +1,52 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTransport(t *testing.T) {
+	var traceparent string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get(HeaderTraceparent)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	exporter := NewMemoryExporter()
+	tracer := NewTracer("notification-service", exporter)
+	client := &http.Client{Transport: NewTransport(tracer, nil)}
+
+	ctx, parent := tracer.Start(context.Background(), "send", SpanKindInternal)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		"http://user:pass@"+srv.Listener.Addr().String()+"/hooks?token=secret#frag", nil)
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	resp.Body.Close()
+	parent.End()
+
+	spans := exporter.Spans()
+	if len(spans) != 2 {
+		t.Fatalf("exported %d spans, want 2", len(spans))
+	}
+	span := spans[0]
+	if span.Kind != SpanKindClient || span.ParentSpanID != parent.SpanContext().SpanID.String() {
+		t.Fatalf("client span kind/parent = %s/%s", span.Kind, span.ParentSpanID)
+	}
+	if want := "00-" + span.TraceID + "-" + span.SpanID + "-01"; traceparent != want {
+		t.Fatalf("server saw traceparent %q, want %q", traceparent, want)
+	}
+	if got, want := span.Attributes["http.url"], "http://"+srv.Listener.Addr().String()+"/hooks"; got != want {
+		t.Fatalf("http.url = %v, want %v", got, want)
+	}
+	if got := span.Attributes["http.status_code"]; got != http.StatusAccepted {
+		t.Fatalf("http.status_code = %v, want %d", got, http.StatusAccepted)
+	}
+}